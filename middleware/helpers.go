@@ -109,7 +109,32 @@ func mergeKeySets(sets ...map[string]struct{}) map[string]struct{} {
 	return merged
 }
 
-func collectHeaders(header http.Header, allowlist map[string]struct{}, redacted map[string]struct{}) logger.Fields {
+type keyPolicy struct {
+	redacted      map[string]struct{}
+	pseudonymized map[string]struct{}
+	pseudonymizer *logger.Pseudonymizer
+}
+
+type maskState struct {
+	pseudonymVersion string
+}
+
+func (p keyPolicy) mask(normalized string, values []string, state *maskState) ([]string, bool) {
+	if _, ok := p.pseudonymized[normalized]; ok && p.pseudonymizer != nil {
+		masked := make([]string, len(values))
+		for i, value := range values {
+			masked[i], state.pseudonymVersion = p.pseudonymizer.Pseudonymize(value)
+		}
+		return masked, true
+	}
+	_, pseudonymized := p.pseudonymized[normalized]
+	if _, ok := p.redacted[normalized]; ok || pseudonymized {
+		return []string{logger.Redacted}, true
+	}
+	return values, false
+}
+
+func collectHeaders(header http.Header, allowlist map[string]struct{}, policy keyPolicy, state *maskState) logger.Fields {
 	if len(header) == 0 {
 		return nil
 	}
@@ -132,10 +157,7 @@ func collectHeaders(header http.Header, allowlist map[string]struct{}, redacted
 		if len(values) == 0 {
 			continue
 		}
-		if _, ok := redacted[normalized]; ok {
-			result[key] = "[REDACTED]"
-			continue
-		}
+		values, _ = policy.mask(normalized, values, state)
 		if len(values) == 1 {
 			result[key] = values[0]
 			continue
@@ -151,7 +173,7 @@ func collectHeaders(header http.Header, allowlist map[string]struct{}, redacted
 	return result
 }
 
-func collectCookies(cookies []*http.Cookie, allowlist map[string]struct{}, redacted map[string]struct{}) logger.Fields {
+func collectCookies(cookies []*http.Cookie, allowlist map[string]struct{}, policy keyPolicy, state *maskState) logger.Fields {
 	if len(cookies) == 0 {
 		return nil
 	}
@@ -167,11 +189,8 @@ func collectCookies(cookies []*http.Cookie, allowlist map[string]struct{}, redac
 				continue
 			}
 		}
-		if _, ok := redacted[normalized]; ok {
-			result[cookie.Name] = "[REDACTED]"
-			continue
-		}
-		result[cookie.Name] = cookie.Value
+		values, _ := policy.mask(normalized, []string{cookie.Value}, state)
+		result[cookie.Name] = values[0]
 	}
 
 	if len(result) == 0 {
@@ -180,7 +199,7 @@ func collectCookies(cookies []*http.Cookie, allowlist map[string]struct{}, redac
 	return result
 }
 
-func sanitizeQuery(rawQuery string, redacted map[string]struct{}) string {
+func sanitizeQuery(rawQuery string, policy keyPolicy, state *maskState) string {
 	if strings.TrimSpace(rawQuery) == "" {
 		return ""
 	}
@@ -190,10 +209,8 @@ func sanitizeQuery(rawQuery string, redacted map[string]struct{}) string {
 		return rawQuery
 	}
 
-	for key := range values {
-		if _, ok := redacted[strings.ToLower(key)]; ok {
-			values[key] = []string{"[REDACTED]"}
-		}
+	for key, items := range values {
+		values[key], _ = policy.mask(strings.ToLower(key), items, state)
 	}
 
 	return values.Encode()
@@ -31,6 +31,16 @@ type structuredLogConfig struct {
 	redactedHeaders    map[string]struct{}
 	redactedCookies    map[string]struct{}
 	redactedQueryParms map[string]struct{}
+	clock              func() time.Time
+
+	pseudonymizer           *logger.Pseudonymizer
+	pseudonymizedHeaders    map[string]struct{}
+	pseudonymizedCookies    map[string]struct{}
+	pseudonymizedQueryParms map[string]struct{}
+
+	bufferDebugLogs bool
+	debugBufferSize int
+	slowThreshold   time.Duration
 }
 
 func defaultStructuredLogConfig() structuredLogConfig {
@@ -177,6 +187,12 @@ func WithRequestIDHeaders(names ...string) StructuredLogOption {
 	}
 }
 
+func WithRequestClock(clock func() time.Time) StructuredLogOption {
+	return func(cfg *structuredLogConfig) {
+		cfg.clock = clock
+	}
+}
+
 func WithRedactedHeaders(names ...string) StructuredLogOption {
 	set := normalizeKeySet(names...)
 	return func(cfg *structuredLogConfig) {
@@ -198,6 +214,41 @@ func WithRedactedQueryParams(names ...string) StructuredLogOption {
 	}
 }
 
+func WithPseudonymizer(pseudonymizer *logger.Pseudonymizer) StructuredLogOption {
+	return func(cfg *structuredLogConfig) {
+		cfg.pseudonymizer = pseudonymizer
+	}
+}
+
+func WithPseudonymizedHeaders(names ...string) StructuredLogOption {
+	set := normalizeKeySet(names...)
+	return func(cfg *structuredLogConfig) {
+		cfg.pseudonymizedHeaders = mergeKeySets(cfg.pseudonymizedHeaders, set)
+	}
+}
+
+func WithPseudonymizedCookies(names ...string) StructuredLogOption {
+	set := normalizeKeySet(names...)
+	return func(cfg *structuredLogConfig) {
+		cfg.pseudonymizedCookies = mergeKeySets(cfg.pseudonymizedCookies, set)
+	}
+}
+
+func WithPseudonymizedQueryParams(names ...string) StructuredLogOption {
+	set := normalizeKeySet(names...)
+	return func(cfg *structuredLogConfig) {
+		cfg.pseudonymizedQueryParms = mergeKeySets(cfg.pseudonymizedQueryParms, set)
+	}
+}
+
+func WithBufferedDebugLogs(maxBytes int, slowThreshold time.Duration) StructuredLogOption {
+	return func(cfg *structuredLogConfig) {
+		cfg.bufferDebugLogs = true
+		cfg.debugBufferSize = maxBytes
+		cfg.slowThreshold = slowThreshold
+	}
+}
+
 func StructuredLogHandler(opts ...StructuredLogOption) gin.HandlerFunc {
 	cfg := defaultStructuredLogConfig()
 	for _, opt := range opts {
@@ -206,15 +257,30 @@ func StructuredLogHandler(opts ...StructuredLogOption) gin.HandlerFunc {
 		}
 	}
 
+	now := cfg.clock
+	if now == nil {
+		now = cfg.logger.Now
+	}
+
+	headerPolicy := keyPolicy{redacted: cfg.redactedHeaders, pseudonymized: cfg.pseudonymizedHeaders, pseudonymizer: cfg.pseudonymizer}
+	cookiePolicy := keyPolicy{redacted: cfg.redactedCookies, pseudonymized: cfg.pseudonymizedCookies, pseudonymizer: cfg.pseudonymizer}
+	queryPolicy := keyPolicy{redacted: cfg.redactedQueryParms, pseudonymized: cfg.pseudonymizedQueryParms, pseudonymizer: cfg.pseudonymizer}
+
 	return func(c *gin.Context) {
 		if cfg.skip != nil && cfg.skip(c) {
 			c.Next()
 			return
 		}
 
-		start := time.Now()
+		start := now()
 		requestLogger := cfg.logger.WithFields(baseRequestFields(c, cfg.requestIDHeaders))
-		attachRequestLogger(c, requestLogger)
+		var debugBuffer *logger.RecordBuffer
+		if cfg.bufferDebugLogs {
+			debugBuffer = logger.NewRecordBuffer(logger.LevelDebug, cfg.debugBufferSize)
+			attachRequestLogger(c, requestLogger.WithRecordBuffer(debugBuffer))
+		} else {
+			attachRequestLogger(c, requestLogger)
+		}
 
 		var bodyCapture *bodyCaptureReadCloser
 		if cfg.includeBody && cfg.bodyLimit > 0 && c.Request.Body != nil && shouldCaptureRequestBody(c.GetHeader("Content-Type")) {
@@ -224,9 +290,10 @@ func StructuredLogHandler(opts ...StructuredLogOption) gin.HandlerFunc {
 
 		c.Next()
 
+		latency := now().Sub(start)
 		fields := logger.Fields{
 			"status":     c.Writer.Status(),
-			"latency_ms": time.Since(start).Milliseconds(),
+			"latency_ms": latency.Milliseconds(),
 		}
 		if size := c.Writer.Size(); size >= 0 {
 			fields["response_bytes"] = size
@@ -236,23 +303,27 @@ func StructuredLogHandler(opts ...StructuredLogOption) gin.HandlerFunc {
 			fields["route"] = route
 		}
 
+		var masking maskState
 		if cfg.includeQuery {
-			if query := sanitizeQuery(c.Request.URL.RawQuery, cfg.redactedQueryParms); query != "" {
+			if query := sanitizeQuery(c.Request.URL.RawQuery, queryPolicy, &masking); query != "" {
 				fields["query"] = query
 			}
 		}
 
 		if cfg.includeHeaders {
-			if headers := collectHeaders(c.Request.Header, cfg.headerAllowlist, cfg.redactedHeaders); len(headers) > 0 {
+			if headers := collectHeaders(c.Request.Header, cfg.headerAllowlist, headerPolicy, &masking); len(headers) > 0 {
 				fields["headers"] = headers
 			}
 		}
 
 		if cfg.includeCookies {
-			if cookies := collectCookies(c.Request.Cookies(), cfg.cookieAllowlist, cfg.redactedCookies); len(cookies) > 0 {
+			if cookies := collectCookies(c.Request.Cookies(), cfg.cookieAllowlist, cookiePolicy, &masking); len(cookies) > 0 {
 				fields["cookies"] = cookies
 			}
 		}
+		if masking.pseudonymVersion != "" {
+			fields[logger.PseudonymVersionKey] = masking.pseudonymVersion
+		}
 
 		if bodyCapture != nil {
 			body := bodyCapture.Bytes()
@@ -290,6 +361,18 @@ func StructuredLogHandler(opts ...StructuredLogOption) gin.HandlerFunc {
 			}
 		}
 
+		if debugBuffer != nil {
+			slow := cfg.slowThreshold > 0 && latency >= cfg.slowThreshold
+			if c.Writer.Status() >= 500 || len(c.Errors) > 0 || slow {
+				_ = debugBuffer.Flush(c.Request.Context())
+				if dropped := debugBuffer.Dropped(); dropped > 0 {
+					fields["debug_logs_dropped"] = dropped
+				}
+			} else {
+				debugBuffer.Discard()
+			}
+		}
+
 		level := cfg.levelResolver(c)
 		message := cfg.successMessage
 		if level >= logger.LevelWarn || lastErr != nil {
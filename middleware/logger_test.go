@@ -8,6 +8,7 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	logger "github.com/PrototypeSirius/ruglogger/ruglog"
 	"github.com/gin-gonic/gin"
@@ -102,6 +103,212 @@ func TestStructuredLogHandlerAddsRequestLoggerAndRedactsSensitiveData(t *testing
 	}
 }
 
+func TestStructuredLogHandlerUsesInjectedClock(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	frozen := time.Date(2026, 5, 11, 13, 0, 0, 0, time.UTC)
+	ticks := 0
+	stepping := func() time.Time {
+		ticks++
+		return frozen.Add(time.Duration(ticks) * 125 * time.Millisecond)
+	}
+
+	var output bytes.Buffer
+	log := logger.MustNew(
+		logger.WithOutput(&output),
+		logger.WithClock(func() time.Time { return frozen }),
+	)
+
+	router := gin.New()
+	router.Use(StructuredLogHandler(
+		WithRequestLogger(log),
+		WithRequestClock(stepping),
+	))
+	router.GET("/healthz", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	records := decodeLogLines(t, output.String())
+	if len(records) != 1 {
+		t.Fatalf("expected 1 log line, got %d: %s", len(records), output.String())
+	}
+	if records[0]["latency_ms"] != float64(125) {
+		t.Fatalf("latency should come from the injected clock: %#v", records[0]["latency_ms"])
+	}
+	if records[0]["timestamp"] != "2026-05-11T13:00:00Z" {
+		t.Fatalf("timestamp should come from the logger clock: %#v", records[0]["timestamp"])
+	}
+}
+
+func TestStructuredLogHandlerPseudonymizesConfiguredKeys(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	pseudonymizer, err := logger.NewPseudonymizer("2026-05", []byte("0123456789abcdef0123"))
+	if err != nil {
+		t.Fatalf("failed to create pseudonymizer: %v", err)
+	}
+
+	var output bytes.Buffer
+	log := logger.MustNew(
+		logger.WithOutput(&output),
+		logger.WithPseudonymization(pseudonymizer, "ip"),
+	)
+
+	router := gin.New()
+	router.Use(StructuredLogHandler(
+		WithRequestLogger(log),
+		WithHeaderLogging("X-User-ID"),
+		WithCookieLogging("tenant"),
+		WithPseudonymizer(pseudonymizer),
+		WithPseudonymizedHeaders("X-User-ID"),
+		WithPseudonymizedCookies("tenant"),
+		WithPseudonymizedQueryParams("email"),
+	))
+	router.GET("/profile", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest(http.MethodGet, "/profile?email=alice@example.com", nil)
+		req.Header.Set("X-User-ID", "user-42")
+		req.AddCookie(&http.Cookie{Name: "tenant", Value: "acme"})
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	records := decodeLogLines(t, output.String())
+	if len(records) != 2 {
+		t.Fatalf("expected 2 log lines, got %d: %s", len(records), output.String())
+	}
+	for _, raw := range []string{"user-42", "acme", "alice", "192.0.2.1"} {
+		if strings.Contains(output.String(), raw) {
+			t.Fatalf("raw value %q leaked: %s", raw, output.String())
+		}
+	}
+
+	userID, _ := pseudonymizer.Pseudonymize("user-42")
+	email, _ := pseudonymizer.Pseudonymize("alice@example.com")
+	first, second := records[0], records[1]
+	if first["headers"].(map[string]any)["X-User-Id"] != userID || first["query"] != "email="+email {
+		t.Fatalf("unexpected pseudonyms: %v", first)
+	}
+	if first["ip"] != second["ip"] || first["cookies"].(map[string]any)["tenant"] != second["cookies"].(map[string]any)["tenant"] {
+		t.Fatalf("pseudonyms should correlate across requests: %v / %v", first, second)
+	}
+	if first[logger.PseudonymVersionKey] != "2026-05" {
+		t.Fatalf("expected key version field, got %v", first[logger.PseudonymVersionKey])
+	}
+}
+
+func TestStructuredLogHandlerNeutralizesHostileRequestData(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var output bytes.Buffer
+	log := logger.MustNew(
+		logger.WithOutput(&output),
+		logger.WithFormat(logger.FormatText),
+	)
+
+	router := gin.New()
+	router.Use(StructuredLogHandler(WithRequestLogger(log)))
+	router.NoRoute(func(c *gin.Context) {
+		c.Status(http.StatusNotFound)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/orders%0D%0Atime=forged%20level=ERROR", nil)
+	req.Header.Set("User-Agent", "bot\x1b[2J\r\nlevel=ERROR msg=forged")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	line := output.String()
+	if strings.Count(line, "\n") != 1 || strings.ContainsAny(line, "\r\x1b") {
+		t.Fatalf("hostile request data reached the log unescaped: %q", line)
+	}
+	if !strings.Contains(line, `bot\\x1b[2J\\r\\nlevel=ERROR`) {
+		t.Fatalf("user agent should be logged in escaped form: %q", line)
+	}
+}
+
+func TestStructuredLogHandlerEmitsBufferedDebugLogsOnlyForFailedRequests(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var output bytes.Buffer
+	log := logger.MustNew(
+		logger.WithOutput(&output),
+		logger.WithLevel(logger.LevelInfo),
+	)
+
+	router := gin.New()
+	router.Use(StructuredLogHandler(
+		WithRequestLogger(log),
+		WithBufferedDebugLogs(1024, time.Hour),
+	))
+	router.GET("/orders/:status", func(c *gin.Context) {
+		RequestLogger(c).Debug("loaded order", logger.Fields{"order_id": 7})
+		logger.FromContext(c.Request.Context()).Trace("cache miss", nil)
+		RequestLogger(c).Info("handled", nil)
+		switch c.Param("status") {
+		case "failed":
+			c.Status(http.StatusBadGateway)
+		case "error":
+			_ = c.Error(io.ErrUnexpectedEOF)
+			c.Status(http.StatusBadRequest)
+		default:
+			c.Status(http.StatusOK)
+		}
+	})
+
+	for _, status := range []string{"ok", "failed", "error"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/orders/"+status, nil))
+	}
+
+	var messages []string
+	for _, record := range decodeLogLines(t, output.String()) {
+		messages = append(messages, record["message"].(string)+" "+record["path"].(string))
+	}
+	expected := []string{
+		"handled /orders/ok",
+		"request completed /orders/ok",
+		"handled /orders/failed",
+		"loaded order /orders/failed",
+		"cache miss /orders/failed",
+		"request failed /orders/failed",
+		"handled /orders/error",
+		"loaded order /orders/error",
+		"cache miss /orders/error",
+		"request failed /orders/error",
+	}
+	if strings.Join(messages, "\n") != strings.Join(expected, "\n") {
+		t.Fatalf("unexpected records:\n%s", strings.Join(messages, "\n"))
+	}
+}
+
+func TestStructuredLogHandlerKeepsDebugLogsWhenLoggerEmitsDebug(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var output bytes.Buffer
+	log := logger.MustNew(
+		logger.WithOutput(&output),
+		logger.WithLevel(logger.LevelDebug),
+	)
+
+	router := gin.New()
+	router.Use(StructuredLogHandler(
+		WithRequestLogger(log),
+		WithBufferedDebugLogs(0, 0),
+	))
+	router.GET("/orders", func(c *gin.Context) {
+		RequestLogger(c).Debug("loaded order", nil)
+		c.Status(http.StatusOK)
+	})
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/orders", nil))
+
+	records := decodeLogLines(t, output.String())
+	if len(records) != 2 || records[0]["message"] != "loaded order" || records[1]["message"] != "request completed" {
+		t.Fatalf("debug records must be written directly when DEBUG is enabled, got %v", records)
+	}
+}
+
 func decodeLogLines(t *testing.T, raw string) []map[string]any {
 	t.Helper()
 
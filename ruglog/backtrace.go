@@ -0,0 +1,91 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+)
+
+const BacktraceKey = "backtrace"
+
+func WithBacktrace(size int, minLevel Level) Option {
+	return func(cfg *config) error {
+		if size <= 0 {
+			return errors.New("logger backtrace size must be positive")
+		}
+		cfg.backtraceSize = size
+		cfg.backtraceLevel = minLevel
+		return nil
+	}
+}
+
+type backtraceContextKey struct{}
+
+func BacktraceContext(ctx context.Context, size int) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if size <= 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, backtraceContextKey{}, newBacktraceRing(size))
+}
+
+func (l *Logger) backtraceRing(ctx context.Context) *backtraceRing {
+	if ring, ok := ctx.Value(backtraceContextKey{}).(*backtraceRing); ok {
+		return ring
+	}
+	return l.state.backtrace
+}
+
+type backtraceEntry struct {
+	handler slog.Handler
+	record  slog.Record
+}
+
+type backtraceRing struct {
+	mu      sync.Mutex
+	entries []backtraceEntry
+	next    int
+	full    bool
+}
+
+func newBacktraceRing(size int) *backtraceRing {
+	return &backtraceRing{entries: make([]backtraceEntry, size)}
+}
+
+func (r *backtraceRing) push(handler slog.Handler, record slog.Record) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.entries[r.next] = backtraceEntry{handler: handler, record: record}
+	r.next = (r.next + 1) % len(r.entries)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+func (r *backtraceRing) drain() []backtraceEntry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var drained []backtraceEntry
+	if r.full {
+		drained = append(drained, r.entries[r.next:]...)
+	}
+	drained = append(drained, r.entries[:r.next]...)
+	clear(r.entries)
+	r.next = 0
+	r.full = false
+	return drained
+}
+
+func (l *Logger) dumpBacktrace(ctx context.Context, ring *backtraceRing) {
+	for _, entry := range ring.drain() {
+		entry.record.AddAttrs(slog.Bool(BacktraceKey, true))
+		if err := handleRecord(ctx, entry.handler, entry.record); err != nil {
+			l.state.reportWriteError(err)
+		}
+	}
+}
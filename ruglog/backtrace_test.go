@@ -0,0 +1,69 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestBacktraceDumpsRecentDebugRecordsBeforeError(t *testing.T) {
+	var output bytes.Buffer
+	log := MustNew(
+		WithOutput(&output),
+		WithLevel(LevelInfo),
+		WithBacktrace(2, LevelDebug),
+	)
+
+	job := log.WithField("job", "sync")
+	job.Trace("ignored trace", nil)
+	job.Debug("step 1", nil)
+	job.Debug("step 2", Fields{"rows": 10})
+	job.Info("visible", nil)
+	job.Debug("step 3", nil)
+	if lines := decodeRecords(t, output.String()); len(lines) != 1 {
+		t.Fatalf("below-threshold records must stay buffered, got %v", lines)
+	}
+
+	job.Error("sync failed", errors.New("timeout"), 0, nil)
+	job.Error("second failure", errors.New("timeout"), 0, nil)
+
+	lines := decodeRecords(t, output.String())
+	messages := make([]any, 0, len(lines))
+	for _, line := range lines {
+		messages = append(messages, line["message"])
+	}
+	expected := []any{"visible", "step 2", "step 3", "sync failed", "second failure"}
+	if len(messages) != len(expected) {
+		t.Fatalf("unexpected records: %v", messages)
+	}
+	for i := range expected {
+		if messages[i] != expected[i] {
+			t.Fatalf("unexpected records: %v", messages)
+		}
+	}
+	if lines[1]["backtrace"] != true || lines[1]["level"] != "DEBUG" || lines[1]["rows"] != float64(10) || lines[1]["job"] != "sync" {
+		t.Fatalf("backtrace record lost its level or fields: %v", lines[1])
+	}
+	if _, marked := lines[3]["backtrace"]; marked {
+		t.Fatalf("the triggering error must not be marked as backtrace: %v", lines[3])
+	}
+}
+
+func TestBacktraceContextIsolatesBuffers(t *testing.T) {
+	var output bytes.Buffer
+	log := MustNew(WithOutput(&output), WithLevel(LevelInfo))
+
+	first := BacktraceContext(context.Background(), 10)
+	second := BacktraceContext(context.Background(), 10)
+	log.DebugContext(first, "first request detail", nil)
+	log.DebugContext(second, "second request detail", nil)
+	log.DebugContext(context.Background(), "no buffer", nil)
+
+	log.ErrorContext(first, "first request failed", nil, 500, nil)
+
+	lines := decodeRecords(t, output.String())
+	if len(lines) != 2 || lines[0]["message"] != "first request detail" || lines[1]["message"] != "first request failed" {
+		t.Fatalf("only the failing context should be dumped, got %v", lines)
+	}
+}
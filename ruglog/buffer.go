@@ -0,0 +1,128 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+)
+
+const defaultRecordBufferBytes = 64 << 10
+
+type RecordBuffer struct {
+	maxLevel Level
+	maxBytes int
+
+	mu      sync.Mutex
+	entries []bufferedRecord
+	size    int
+	dropped int
+}
+
+type bufferedRecord struct {
+	logger *Logger
+	record slog.Record
+	size   int
+}
+
+func NewRecordBuffer(maxLevel Level, maxBytes int) *RecordBuffer {
+	if maxBytes <= 0 {
+		maxBytes = defaultRecordBufferBytes
+	}
+	return &RecordBuffer{maxLevel: maxLevel, maxBytes: maxBytes}
+}
+
+func (l *Logger) WithRecordBuffer(buffer *RecordBuffer) *Logger {
+	log := l.effective()
+	child := log.clone(log.base)
+	child.buffer = buffer
+	return child
+}
+
+func (b *RecordBuffer) holds(level Level) bool {
+	return b != nil && level <= b.maxLevel && level < LevelFatal
+}
+
+func (b *RecordBuffer) add(log *Logger, record slog.Record) {
+	size := estimateRecordSize(record)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if size > b.maxBytes {
+		b.dropped++
+		return
+	}
+	b.entries = append(b.entries, bufferedRecord{logger: log, record: record, size: size})
+	b.size += size
+	for b.size > b.maxBytes {
+		b.size -= b.entries[0].size
+		b.entries[0] = bufferedRecord{}
+		b.entries = b.entries[1:]
+		b.dropped++
+	}
+}
+
+func (b *RecordBuffer) take() []bufferedRecord {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	entries := b.entries
+	b.entries = nil
+	b.size = 0
+	return entries
+}
+
+func (b *RecordBuffer) Flush(ctx context.Context) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	var joined error
+	for _, entry := range b.take() {
+		if err := handleRecord(ctx, entry.logger.base.Handler(), entry.record); err != nil {
+			entry.logger.state.reportWriteError(err)
+			joined = errors.Join(joined, err)
+		}
+	}
+	return joined
+}
+
+func (b *RecordBuffer) Discard() {
+	b.take()
+}
+
+func (b *RecordBuffer) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.entries)
+}
+
+func (b *RecordBuffer) Dropped() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.dropped
+}
+
+func estimateRecordSize(record slog.Record) int {
+	size := len(record.Message) + 64
+	record.Attrs(func(attr slog.Attr) bool {
+		size += estimateAttrSize(attr)
+		return true
+	})
+	return size
+}
+
+func estimateAttrSize(attr slog.Attr) int {
+	size := len(attr.Key) + 4
+	value := attr.Value
+	if value.Kind() == slog.KindLogValuer {
+		return size + 16
+	}
+	if value.Kind() != slog.KindGroup {
+		return size + len(value.String())
+	}
+	for _, child := range value.Group() {
+		size += estimateAttrSize(child)
+	}
+	return size
+}
@@ -0,0 +1,88 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecordBufferHoldsLowLevelRecordsWithinByteCap(t *testing.T) {
+	var output bytes.Buffer
+	log := MustNew(WithOutput(&output), WithLevel(LevelWarn))
+
+	buffer := NewRecordBuffer(LevelDebug, 300)
+	request := log.WithRecordBuffer(buffer).WithField("request_id", "req-1")
+
+	for _, msg := range []string{"first", "second", "third"} {
+		request.Debug(msg, Fields{"payload": strings.Repeat("x", 40)})
+	}
+	request.Warn("slow upstream", 0, nil)
+	if buffer.Len() != 2 || buffer.Dropped() != 1 {
+		t.Fatalf("expected the oldest record to be evicted, got len=%d dropped=%d", buffer.Len(), buffer.Dropped())
+	}
+	if records := decodeRecords(t, output.String()); len(records) != 1 || records[0]["message"] != "slow upstream" {
+		t.Fatalf("records at or above WARN must bypass the buffer, got %v", records)
+	}
+
+	if err := buffer.Flush(context.Background()); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+	records := decodeRecords(t, output.String())
+	if len(records) != 3 || records[1]["message"] != "second" || records[2]["message"] != "third" || records[2]["request_id"] != "req-1" {
+		t.Fatalf("unexpected flushed records: %v", records)
+	}
+
+	request.Debug("discarded", nil)
+	buffer.Discard()
+	if err := buffer.Flush(context.Background()); err != nil || strings.Contains(output.String(), "discarded") {
+		t.Fatalf("discarded records must not be written: %v %s", err, output.String())
+	}
+}
+
+func TestFlushedRecordsKeepTheTimeTheyWereLogged(t *testing.T) {
+	var output bytes.Buffer
+	now := time.Date(2026, 5, 11, 13, 0, 0, 0, time.UTC)
+	log := MustNew(WithOutput(&output), WithLevel(LevelInfo), WithClock(func() time.Time { return now }))
+
+	buffer := NewRecordBuffer(LevelDebug, 1024)
+	log.WithRecordBuffer(buffer).Debug("cache miss", nil)
+	now = now.Add(time.Minute)
+	if err := buffer.Flush(context.Background()); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+	if record := decodeSingleRecord(t, output.String()); record["timestamp"] != "2026-05-11T13:00:00Z" {
+		t.Fatalf("flushed record must keep its original time, got %v", record["timestamp"])
+	}
+}
+
+func TestRecordBufferPassesThroughLevelsTheLoggerEmits(t *testing.T) {
+	var output bytes.Buffer
+	log := MustNew(WithOutput(&output), WithLevel(LevelDebug))
+
+	buffer := NewRecordBuffer(LevelDebug, 1024)
+	request := log.WithRecordBuffer(buffer)
+	request.Debug("cache miss", nil)
+	request.Trace("lookup", nil)
+	buffer.Discard()
+
+	if records := decodeRecords(t, output.String()); len(records) != 1 || records[0]["message"] != "cache miss" {
+		t.Fatalf("enabled DEBUG must be written immediately, got %v", records)
+	}
+	if err := buffer.Flush(context.Background()); err != nil || strings.Contains(output.String(), "lookup") {
+		t.Fatalf("disabled TRACE must stay in the buffer: %v %s", err, output.String())
+	}
+}
+
+func TestRecordBufferWithoutLimitUsesDefaultCap(t *testing.T) {
+	log := MustNew(WithOutput(&bytes.Buffer{}), WithLevel(LevelInfo))
+	buffer := NewRecordBuffer(LevelDebug, 0)
+	request := log.WithRecordBuffer(buffer)
+	for range 100 {
+		request.Debug("payload", Fields{"body": strings.Repeat("x", 1024)})
+	}
+	if buffer.Dropped() == 0 || buffer.Len() >= 100 {
+		t.Fatalf("a non-positive limit must still cap the buffer, got len=%d dropped=%d", buffer.Len(), buffer.Dropped())
+	}
+}
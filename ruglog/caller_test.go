@@ -0,0 +1,34 @@
+package logger_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	logger "github.com/PrototypeSirius/ruglogger/ruglog"
+)
+
+func TestSourcePointsAtTheCallerOutsideThePackage(t *testing.T) {
+	var output bytes.Buffer
+	log := logger.MustNew(logger.WithOutput(&output), logger.WithAddSource(true))
+
+	log.Info("plain", nil)
+	log.Infof("formatted %d", 1)
+	log.WithField("component", "worker").Warnw("key-value", "attempt", 2)
+
+	for _, line := range strings.Split(strings.TrimSpace(output.String()), "\n") {
+		var record struct {
+			Source struct {
+				Function string `json:"function"`
+				File     string `json:"file"`
+			} `json:"source"`
+		}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("invalid JSON %q: %v", line, err)
+		}
+		if !strings.HasSuffix(record.Source.File, "caller_test.go") || !strings.HasSuffix(record.Source.Function, "TestSourcePointsAtTheCallerOutsideThePackage") {
+			t.Fatalf("source must point at the test function: %s", line)
+		}
+	}
+}
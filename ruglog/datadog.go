@@ -0,0 +1,135 @@
+package logger
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+const (
+	datadogIntakePath = "/api/v2/logs"
+	datadogSinkScope  = sinkScopeHTTP | sinkScopeHost | sinkScopeToken | sinkScopeDatadog
+)
+
+func WithDatadogSource(source string) SinkOption {
+	return func(cfg *sinkConfig) error {
+		cfg.use("WithDatadogSource", sinkScopeDatadog)
+		cfg.source = strings.TrimSpace(source)
+		return nil
+	}
+}
+
+func WithDatadogService(service string) SinkOption {
+	return func(cfg *sinkConfig) error {
+		cfg.use("WithDatadogService", sinkScopeDatadog)
+		cfg.service = strings.TrimSpace(service)
+		return nil
+	}
+}
+
+func WithDatadogTags(tags ...string) SinkOption {
+	return func(cfg *sinkConfig) error {
+		cfg.use("WithDatadogTags", sinkScopeDatadog)
+		for _, tag := range tags {
+			tag = strings.TrimSpace(tag)
+			if tag == "" {
+				continue
+			}
+			cfg.tags = append(cfg.tags, tag)
+		}
+		return nil
+	}
+}
+
+type DatadogSender struct {
+	endpoint string
+	host     string
+	cfg      sinkConfig
+}
+
+func NewDatadogSender(endpoint string, opts ...SinkOption) (*DatadogSender, error) {
+	cfg, err := newSinkConfig("datadog", datadogSinkScope, opts)
+	if err != nil {
+		return nil, err
+	}
+	return newDatadogSender(endpoint, cfg)
+}
+
+func newDatadogSender(endpoint string, cfg sinkConfig) (*DatadogSender, error) {
+	if cfg.token == "" {
+		return nil, errors.New("datadog API key is required")
+	}
+	intakeURL, err := sinkEndpoint(endpoint, datadogIntakePath)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.source == "" {
+		cfg.source = "go"
+	}
+	return &DatadogSender{
+		endpoint: intakeURL,
+		host:     sinkHost(cfg),
+		cfg:      cfg,
+	}, nil
+}
+
+func NewDatadogWriter(endpoint string, opts ...SinkOption) (*BatchWriter, error) {
+	cfg, err := newSinkConfig("datadog", datadogSinkScope|sinkScopeBatch, opts)
+	if err != nil {
+		return nil, err
+	}
+	sender, err := newDatadogSender(endpoint, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return newBatchWriter(sender, cfg)
+}
+
+func (s *DatadogSender) SendBatch(ctx context.Context, lines [][]byte) error {
+	if len(lines) == 0 {
+		return nil
+	}
+
+	entries := make([]map[string]any, 0, len(lines))
+	for _, line := range lines {
+		entries = append(entries, s.entry(decodeRecordLine(line)))
+	}
+	body, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+
+	headers := http.Header{}
+	headers.Set("DD-API-KEY", s.cfg.token)
+	_, err = postSinkRequest(ctx, s.cfg, s.endpoint, "application/json", body, headers)
+	return err
+}
+
+func (s *DatadogSender) entry(record map[string]any) map[string]any {
+	if status, ok := record["status"]; ok {
+		delete(record, "status")
+		if httpFields, ok := record["http"].(map[string]any); ok {
+			httpFields["status_code"] = status
+		} else if _, exists := record["http"]; !exists {
+			record["http"] = map[string]any{"status_code": status}
+		} else {
+			record["status_code"] = status
+		}
+	}
+
+	if level := recordString(record, "level"); level != "" {
+		record["status"] = strings.ToLower(level)
+		delete(record, "level")
+	}
+	record["ddsource"] = s.cfg.source
+	record["hostname"] = s.host
+	if _, ok := record["service"]; !ok && s.cfg.service != "" {
+		record["service"] = s.cfg.service
+	}
+	if len(s.cfg.tags) > 0 {
+		record["ddtags"] = strings.Join(s.cfg.tags, ",")
+	}
+	return record
+}
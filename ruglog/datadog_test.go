@@ -0,0 +1,58 @@
+package logger
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestDatadogWriterMapsRecordsToIntakeEntries(t *testing.T) {
+	var entries []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v2/logs" || r.Header.Get("DD-API-KEY") != "dd-key" {
+			t.Errorf("unexpected intake request: %s %v", r.URL.Path, r.Header)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&entries); err != nil {
+			t.Errorf("invalid intake payload: %v", err)
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	sink, err := NewDatadogWriter(server.URL,
+		WithSinkToken("dd-key"),
+		WithSinkHost("api-1"),
+		WithDatadogService("fallback"),
+		WithDatadogTags("env:prod", "team:payments"),
+		WithBatchAge(time.Hour),
+	)
+	if err != nil {
+		t.Fatalf("failed to create datadog writer: %v", err)
+	}
+
+	log := MustNew(WithOutput(sink), WithField("service", "orders-api"))
+	log.Warn("request failed", 0, Fields{"status": 502})
+	if err := sink.Close(); err != nil {
+		t.Fatalf("close failed: %v", err)
+	}
+
+	if len(entries) != 1 {
+		t.Fatalf("expected one entry, got %d", len(entries))
+	}
+	entry := entries[0]
+	if entry["status"] != "warn" || entry["message"] != "request failed" {
+		t.Fatalf("unexpected status or message: %#v", entry)
+	}
+	if entry["ddsource"] != "go" || entry["ddtags"] != "env:prod,team:payments" || entry["hostname"] != "api-1" {
+		t.Fatalf("unexpected datadog metadata: %#v", entry)
+	}
+	if entry["service"] != "orders-api" {
+		t.Fatalf("record service should win over the default: %#v", entry["service"])
+	}
+	httpFields, ok := entry["http"].(map[string]any)
+	if !ok || httpFields["status_code"] != float64(502) {
+		t.Fatalf("http status should move to http.status_code: %#v", entry)
+	}
+}
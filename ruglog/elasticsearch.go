@@ -0,0 +1,177 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	elasticBulkPath  = "/_bulk"
+	elasticSinkScope = sinkScopeHTTP | sinkScopeElastic
+)
+
+func WithElasticIndex(prefix string, dateLayout string) SinkOption {
+	return func(cfg *sinkConfig) error {
+		cfg.use("WithElasticIndex", sinkScopeElastic)
+		prefix = strings.TrimSpace(prefix)
+		if prefix == "" {
+			return errors.New("elasticsearch index prefix cannot be empty")
+		}
+		if strings.ToLower(prefix) != prefix {
+			return fmt.Errorf("elasticsearch index prefix %q must be lowercase", prefix)
+		}
+		cfg.indexPrefix = prefix
+		cfg.indexLayout = strings.TrimSpace(dateLayout)
+		return nil
+	}
+}
+
+type ElasticsearchSender struct {
+	endpoint string
+	cfg      sinkConfig
+	now      func() time.Time
+}
+
+func NewElasticsearchSender(endpoint string, opts ...SinkOption) (*ElasticsearchSender, error) {
+	cfg, err := newSinkConfig("elasticsearch", elasticSinkScope, opts)
+	if err != nil {
+		return nil, err
+	}
+	return newElasticsearchSender(endpoint, cfg)
+}
+
+func newElasticsearchSender(endpoint string, cfg sinkConfig) (*ElasticsearchSender, error) {
+	bulkURL, err := sinkEndpoint(endpoint, elasticBulkPath)
+	if err != nil {
+		return nil, err
+	}
+	return &ElasticsearchSender{
+		endpoint: bulkURL,
+		cfg:      cfg,
+		now:      time.Now,
+	}, nil
+}
+
+func NewElasticsearchWriter(endpoint string, opts ...SinkOption) (*BatchWriter, error) {
+	cfg, err := newSinkConfig("elasticsearch", elasticSinkScope|sinkScopeBatch, opts)
+	if err != nil {
+		return nil, err
+	}
+	sender, err := newElasticsearchSender(endpoint, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return newBatchWriter(sender, cfg)
+}
+
+type elasticBulkResponse struct {
+	Errors bool                                 `json:"errors"`
+	Items  []map[string]elasticBulkItemResponse `json:"items"`
+}
+
+type elasticBulkItemResponse struct {
+	Status int `json:"status"`
+	Error  struct {
+		Type   string `json:"type"`
+		Reason string `json:"reason"`
+	} `json:"error"`
+}
+
+func (s *ElasticsearchSender) SendBatch(ctx context.Context, lines [][]byte) error {
+	if len(lines) == 0 {
+		return nil
+	}
+
+	body, err := s.encodeBulk(lines)
+	if err != nil {
+		return err
+	}
+	payload, err := postSinkRequest(ctx, s.cfg, s.endpoint, "application/x-ndjson", body, nil)
+	if err != nil {
+		return err
+	}
+
+	var response elasticBulkResponse
+	if err := json.Unmarshal(payload, &response); err != nil {
+		return &PartialBatchError{Dropped: len(lines), Err: fmt.Errorf("invalid bulk response: %w", err)}
+	}
+	if !response.Errors {
+		return nil
+	}
+	if len(response.Items) != len(lines) {
+		return &PartialBatchError{
+			Dropped: len(lines),
+			Err:     fmt.Errorf("bulk response has %d items for %d records", len(response.Items), len(lines)),
+		}
+	}
+
+	partial := &PartialBatchError{}
+	var retryErr, dropErr error
+	for i, item := range response.Items {
+		for _, result := range item {
+			if result.Status >= 200 && result.Status <= 299 {
+				continue
+			}
+			itemErr := fmt.Errorf("bulk item failed with status %d: %s: %s", result.Status, result.Error.Type, result.Error.Reason)
+			if result.Status == http.StatusTooManyRequests || result.Status >= 500 {
+				partial.Retry = append(partial.Retry, lines[i])
+				if retryErr == nil {
+					retryErr = itemErr
+				}
+				continue
+			}
+			partial.Dropped++
+			if dropErr == nil {
+				dropErr = itemErr
+			}
+		}
+	}
+	switch {
+	case dropErr != nil:
+		partial.Err = dropErr
+	case retryErr != nil:
+		partial.Err = retryErr
+	default:
+		return nil
+	}
+	return partial
+}
+
+func (s *ElasticsearchSender) encodeBulk(lines [][]byte) ([]byte, error) {
+	var buffer bytes.Buffer
+	now := s.now()
+	for _, line := range lines {
+		record := decodeRecordLine(line)
+		timestamp := recordTime(record, now)
+		record["@timestamp"] = timestamp.UTC().Format(time.RFC3339Nano)
+
+		action, err := json.Marshal(map[string]map[string]string{
+			"create": {"_index": s.indexName(timestamp)},
+		})
+		if err != nil {
+			return nil, err
+		}
+		document, err := json.Marshal(record)
+		if err != nil {
+			return nil, err
+		}
+		buffer.Write(action)
+		buffer.WriteByte('\n')
+		buffer.Write(document)
+		buffer.WriteByte('\n')
+	}
+	return buffer.Bytes(), nil
+}
+
+func (s *ElasticsearchSender) indexName(timestamp time.Time) string {
+	if s.cfg.indexLayout == "" {
+		return s.cfg.indexPrefix
+	}
+	return s.cfg.indexPrefix + timestamp.UTC().Format(s.cfg.indexLayout)
+}
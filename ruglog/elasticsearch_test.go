@@ -0,0 +1,109 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestElasticsearchWriterRetriesOnlyRetryableItems(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests [][]map[string]any
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/_bulk" || r.Header.Get("Content-Type") != "application/x-ndjson" {
+			t.Errorf("unexpected bulk request: %s %v", r.URL.Path, r.Header)
+		}
+		body, _ := io.ReadAll(r.Body)
+		lines := decodeNDJSONForTest(t, body)
+
+		mu.Lock()
+		requests = append(requests, lines)
+		call := len(requests)
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		if call == 1 {
+			_, _ = io.WriteString(w, `{"errors":true,"items":[
+				{"create":{"status":201}},
+				{"create":{"status":429,"error":{"type":"es_rejected_execution_exception","reason":"queue full"}}},
+				{"create":{"status":400,"error":{"type":"mapper_parsing_exception","reason":"bad field"}}}
+			]}`)
+			return
+		}
+		_, _ = io.WriteString(w, `{"errors":false,"items":[{"create":{"status":201}}]}`)
+	}))
+	defer server.Close()
+
+	var reported []error
+	sink, err := NewElasticsearchWriter(server.URL,
+		WithElasticIndex("orders-", "2006.01"),
+		WithBatchAge(time.Hour),
+		WithRetry(3, time.Millisecond, time.Millisecond),
+		WithSinkErrorHandler(func(err error) {
+			reported = append(reported, err)
+		}),
+	)
+	if err != nil {
+		t.Fatalf("failed to create elasticsearch writer: %v", err)
+	}
+
+	for _, line := range []string{
+		`{"timestamp":"2026-05-11T13:00:00Z","level":"INFO","message":"first"}`,
+		`{"timestamp":"2026-05-11T13:00:01Z","level":"INFO","message":"second"}`,
+		`{"timestamp":"2026-05-11T13:00:02Z","level":"INFO","message":"third"}`,
+	} {
+		if _, err := sink.Write([]byte(line + "\n")); err != nil {
+			t.Fatalf("write failed: %v", err)
+		}
+	}
+	closeErr := sink.Close()
+
+	if len(requests) != 2 {
+		t.Fatalf("expected 2 bulk requests, got %d", len(requests))
+	}
+	first := requests[0]
+	if len(first) != 6 {
+		t.Fatalf("expected 3 action/document pairs, got %d lines", len(first))
+	}
+	action := first[0]["create"].(map[string]any)
+	if action["_index"] != "orders-2026.05" {
+		t.Fatalf("unexpected index: %#v", action["_index"])
+	}
+	if first[1]["@timestamp"] != "2026-05-11T13:00:00Z" {
+		t.Fatalf("missing @timestamp: %#v", first[1])
+	}
+	if retried := requests[1]; len(retried) != 2 || retried[1]["message"] != "second" {
+		t.Fatalf("expected only the throttled record to be retried, got %#v", retried)
+	}
+
+	if stats := sink.Stats(); stats.Sent != 2 || stats.Dropped != 1 || stats.Retries != 1 {
+		t.Fatalf("unexpected sink stats: %+v", stats)
+	}
+	if closeErr == nil || len(reported) != 1 || !strings.Contains(reported[0].Error(), "mapper_parsing_exception") {
+		t.Fatalf("expected dropped item to be reported, got close error %v and %d reports", closeErr, len(reported))
+	}
+}
+
+func decodeNDJSONForTest(t *testing.T, body []byte) []map[string]any {
+	t.Helper()
+
+	var lines []map[string]any
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	for scanner.Scan() {
+		var line map[string]any
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf("invalid ndjson line %q: %v", scanner.Text(), err)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
@@ -0,0 +1,298 @@
+package logger
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultGELFChunkSize = 1420
+	gelfChunkHeaderSize  = 12
+	gelfMaxChunks        = 128
+
+	gelfSinkScope = sinkScopeHost | sinkScopeGELF
+)
+
+type GELFCompression string
+
+const (
+	GELFCompressNone GELFCompression = "none"
+	GELFCompressGzip GELFCompression = "gzip"
+	GELFCompressZlib GELFCompression = "zlib"
+)
+
+func WithGELFCompression(compression GELFCompression) SinkOption {
+	return func(cfg *sinkConfig) error {
+		cfg.use("WithGELFCompression", sinkScopeGELF)
+		switch compression {
+		case GELFCompressNone, GELFCompressGzip, GELFCompressZlib:
+			cfg.gelfCompression = compression
+			return nil
+		default:
+			return fmt.Errorf("unsupported gelf compression %q", compression)
+		}
+	}
+}
+
+func WithGELFChunkSize(size int) SinkOption {
+	return func(cfg *sinkConfig) error {
+		cfg.use("WithGELFChunkSize", sinkScopeGELF)
+		if size <= gelfChunkHeaderSize {
+			return fmt.Errorf("gelf chunk size must be greater than %d, got %d", gelfChunkHeaderSize, size)
+		}
+		cfg.gelfChunkSize = size
+		return nil
+	}
+}
+
+func WithGELFOutput(network string, address string, opts ...SinkOption) Option {
+	return func(cfg *config) error {
+		writer, err := NewGELFWriter(network, address, opts...)
+		if err != nil {
+			return err
+		}
+		return WithSink(writer)(cfg)
+	}
+}
+
+type GELFWriter struct {
+	network string
+	address string
+	host    string
+	cfg     sinkConfig
+	now     func() time.Time
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+func NewGELFWriter(network string, address string, opts ...SinkOption) (*GELFWriter, error) {
+	network = strings.ToLower(strings.TrimSpace(network))
+	if network != "udp" && network != "tcp" {
+		return nil, fmt.Errorf("unsupported gelf network %q", network)
+	}
+	if strings.TrimSpace(address) == "" {
+		return nil, errors.New("gelf address cannot be empty")
+	}
+	cfg, err := newSinkConfig("gelf", gelfSinkScope, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &GELFWriter{
+		network: network,
+		address: address,
+		host:    sinkHost(cfg),
+		cfg:     cfg,
+		now:     time.Now,
+	}, nil
+}
+
+func (w *GELFWriter) Write(p []byte) (int, error) {
+	line := bytes.TrimRight(p, "\r\n")
+	if len(line) == 0 {
+		return len(p), nil
+	}
+
+	payload, err := json.Marshal(w.message(decodeRecordLine(line)))
+	if err != nil {
+		return 0, err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if err := w.send(payload); err != nil {
+		if w.conn != nil {
+			_ = w.conn.Close()
+			w.conn = nil
+		}
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (w *GELFWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.conn == nil {
+		return nil
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
+
+func (w *GELFWriter) send(payload []byte) error {
+	if w.conn == nil {
+		conn, err := net.DialTimeout(w.network, w.address, w.cfg.timeout)
+		if err != nil {
+			return err
+		}
+		w.conn = conn
+	}
+	_ = w.conn.SetWriteDeadline(time.Now().Add(w.cfg.timeout))
+
+	if w.network == "tcp" {
+		_, err := w.conn.Write(append(payload, 0))
+		return err
+	}
+
+	compressed, err := compressGELF(payload, w.cfg.gelfCompression)
+	if err != nil {
+		return err
+	}
+	chunks, err := chunkGELF(compressed, w.cfg.gelfChunkSize)
+	if err != nil {
+		return err
+	}
+	for _, chunk := range chunks {
+		if _, err := w.conn.Write(chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *GELFWriter) message(record map[string]any) map[string]any {
+	timestamp := recordTime(record, w.now())
+	message := recordString(record, "message")
+	if message == "" {
+		message = "-"
+	}
+
+	gelf := map[string]any{
+		"version":       "1.1",
+		"host":          w.host,
+		"short_message": message,
+		"timestamp":     json.Number(fmt.Sprintf("%d.%03d", timestamp.Unix(), timestamp.Nanosecond()/int(time.Millisecond))),
+		"level":         parseRecordLevel(recordString(record, "level")).SyslogSeverity(),
+	}
+
+	keys := make([]string, 0, len(record))
+	for key := range record {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		switch key {
+		case "message", "level", "timestamp":
+			continue
+		}
+		addGELFField(gelf, gelfFieldName(key), record[key])
+	}
+	return gelf
+}
+
+func addGELFField(gelf map[string]any, name string, value any) {
+	switch typed := value.(type) {
+	case nil:
+	case string, json.Number:
+		gelf[name] = typed
+	case bool:
+		gelf[name] = fmt.Sprint(typed)
+	case map[string]any:
+		for key, nested := range typed {
+			addGELFField(gelf, name+"_"+sanitizeGELFKey(key), nested)
+		}
+	default:
+		encoded, err := json.Marshal(typed)
+		if err != nil {
+			gelf[name] = fmt.Sprint(typed)
+			return
+		}
+		gelf[name] = string(encoded)
+	}
+}
+
+func gelfFieldName(key string) string {
+	key = sanitizeGELFKey(key)
+	if key == "id" {
+		return "_id_"
+	}
+	return "_" + key
+}
+
+func sanitizeGELFKey(key string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '.', r == '-':
+			return r
+		default:
+			return '_'
+		}
+	}, key)
+}
+
+func compressGELF(payload []byte, compression GELFCompression) ([]byte, error) {
+	var buffer bytes.Buffer
+	var writer io.WriteCloser
+	switch compression {
+	case GELFCompressNone:
+		return payload, nil
+	case GELFCompressZlib:
+		writer = zlib.NewWriter(&buffer)
+	default:
+		writer = gzip.NewWriter(&buffer)
+	}
+	if _, err := writer.Write(payload); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+func chunkGELF(payload []byte, chunkSize int) ([][]byte, error) {
+	if len(payload) <= chunkSize {
+		return [][]byte{payload}, nil
+	}
+
+	dataSize := chunkSize - gelfChunkHeaderSize
+	count := (len(payload) + dataSize - 1) / dataSize
+	if count > gelfMaxChunks {
+		return nil, fmt.Errorf("gelf message needs %d chunks, limit is %d", count, gelfMaxChunks)
+	}
+
+	var id [8]byte
+	binary.BigEndian.PutUint64(id[:], rand.Uint64())
+
+	chunks := make([][]byte, 0, count)
+	for i := 0; i < count; i++ {
+		start := i * dataSize
+		end := min(start+dataSize, len(payload))
+		chunk := make([]byte, 0, gelfChunkHeaderSize+end-start)
+		chunk = append(chunk, 0x1e, 0x0f)
+		chunk = append(chunk, id[:]...)
+		chunk = append(chunk, byte(i), byte(count))
+		chunk = append(chunk, payload[start:end]...)
+		chunks = append(chunks, chunk)
+	}
+	return chunks, nil
+}
+
+func parseRecordLevel(value string) Level {
+	if level, err := ParseLevel(value); err == nil {
+		return level
+	}
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(value)); err == nil {
+		return Level(level)
+	}
+	return LevelInfo
+}
@@ -0,0 +1,154 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGELFWriterChunksCompressedUDPMessages(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen on udp: %v", err)
+	}
+	defer conn.Close()
+
+	writer, err := NewGELFWriter("udp", conn.LocalAddr().String(),
+		WithSinkHost("worker-1"),
+		WithGELFChunkSize(64),
+	)
+	if err != nil {
+		t.Fatalf("failed to create gelf writer: %v", err)
+	}
+	defer writer.Close()
+
+	log := MustNew(WithOutput(writer), WithLevel(LevelDebug))
+	log.Error("payment failed", io.ErrUnexpectedEOF, 17, Fields{
+		"id":      "pay-1",
+		"payload": strings.Repeat("0123456789abcdef", 32),
+		"retry":   Fields{"attempt": 3},
+	})
+
+	chunks := map[byte][]byte{}
+	var total byte
+	buffer := make([]byte, 2048)
+	for total == 0 || len(chunks) < int(total) {
+		_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		n, _, err := conn.ReadFrom(buffer)
+		if err != nil {
+			t.Fatalf("failed to read chunk %d/%d: %v", len(chunks), total, err)
+		}
+		packet := buffer[:n]
+		if n > 64 || packet[0] != 0x1e || packet[1] != 0x0f {
+			t.Fatalf("unexpected chunk of %d bytes: %x", n, packet[:2])
+		}
+		total = packet[11]
+		chunks[packet[10]] = append([]byte(nil), packet[12:]...)
+	}
+
+	sequence := make([]int, 0, len(chunks))
+	for seq := range chunks {
+		sequence = append(sequence, int(seq))
+	}
+	sort.Ints(sequence)
+	var compressed []byte
+	for _, seq := range sequence {
+		compressed = append(compressed, chunks[byte(seq)]...)
+	}
+
+	reader, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		t.Fatalf("chunks are not gzip compressed: %v", err)
+	}
+	var message map[string]any
+	if err := json.NewDecoder(reader).Decode(&message); err != nil {
+		t.Fatalf("invalid gelf payload: %v", err)
+	}
+
+	if message["version"] != "1.1" || message["host"] != "worker-1" {
+		t.Fatalf("unexpected gelf envelope: %#v", message)
+	}
+	if message["short_message"] != "payment failed" || message["level"] != float64(3) {
+		t.Fatalf("unexpected gelf message or level: %#v", message)
+	}
+	if message["_app_code"] != float64(17) || message["_error"] != "unexpected EOF" {
+		t.Fatalf("missing additional fields: %#v", message)
+	}
+	if message["_retry_attempt"] != float64(3) || message["_id_"] != "pay-1" {
+		t.Fatalf("unexpected nested or reserved fields: %#v", message)
+	}
+}
+
+func TestGELFWriterSendsNullDelimitedTCPFrames(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen on tcp: %v", err)
+	}
+	defer listener.Close()
+
+	frames := make(chan string, 2)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		reader := bufio.NewReader(conn)
+		for {
+			frame, err := reader.ReadString(0)
+			if err != nil {
+				return
+			}
+			frames <- strings.TrimSuffix(frame, "\x00")
+		}
+	}()
+
+	writer, err := NewGELFWriter("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatalf("failed to create gelf writer: %v", err)
+	}
+	defer writer.Close()
+
+	log := MustNew(WithOutput(writer), WithLevel(LevelDebug))
+	log.Debug("first", nil)
+	log.Warn("second", 0, Fields{"enabled": true})
+
+	for _, expected := range []struct {
+		message string
+		level   float64
+	}{{"first", 7}, {"second", 4}} {
+		select {
+		case frame := <-frames:
+			var message map[string]any
+			if err := json.Unmarshal([]byte(frame), &message); err != nil {
+				t.Fatalf("invalid tcp frame %q: %v", frame, err)
+			}
+			if message["short_message"] != expected.message || message["level"] != expected.level {
+				t.Fatalf("unexpected tcp frame: %#v", message)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timed out waiting for %q", expected.message)
+		}
+	}
+}
+
+func TestGELFWriterRejectsQueueOptions(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "spool")
+	for _, opt := range []SinkOption{WithSpool(dir), WithBatchSize(10, 0), WithRetry(3, 0, 0), WithQueueSize(100)} {
+		if _, err := NewGELFWriter("udp", "127.0.0.1:12201", opt); err == nil || !strings.Contains(err.Error(), "gelf sink") {
+			t.Fatalf("gelf writer sends records immediately and must reject queue options, got %v", err)
+		}
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Fatalf("rejected spool option must not create a directory: %v", err)
+	}
+}
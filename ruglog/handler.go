@@ -0,0 +1,408 @@
+package logger
+
+import (
+	"context"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"slices"
+	"time"
+)
+
+var (
+	jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+	logValuerType     = reflect.TypeFor[slog.LogValuer]()
+	stringerType      = reflect.TypeFor[fmt.Stringer]()
+)
+
+type pipeline struct {
+	redactedKeys      map[string]struct{}
+	scrubber          *scrubber
+	pseudonymizer     *Pseudonymizer
+	pseudonymizedKeys map[string]struct{}
+
+	escapeControlChars bool
+	jsonEscapes        bool
+	maxMessageLength   int
+	maxValueLength     int
+	durationEncoding   DurationEncoding
+	maxDepth           int
+	duplicateKeys      DuplicateKeyPolicy
+	reservedKeys       map[string]struct{}
+	keyLayout          KeyLayout
+
+	clock   func() time.Time
+	hooks   *hookRegistry
+	metrics *metrics
+}
+
+type rewriteState struct {
+	pseudonymVersion string
+	visiting         map[visitKey]struct{}
+}
+
+func newPipeline(cfg config) *pipeline {
+	return &pipeline{
+		redactedKeys:      cfg.redactedKeys,
+		scrubber:          newScrubber(cfg.scrubModes),
+		pseudonymizer:     cfg.pseudonymizer,
+		pseudonymizedKeys: cfg.pseudonymizedKeys,
+
+		escapeControlChars: cfg.escapeControlChars,
+		jsonEscapes:        cfg.handler == nil && cfg.format == FormatJSON,
+		maxMessageLength:   cfg.maxMessageLength,
+		maxValueLength:     cfg.maxValueLength,
+		durationEncoding:   cfg.durationEncoding,
+		maxDepth:           cfg.maxDepth,
+		duplicateKeys:      cfg.duplicateKeys,
+		reservedKeys:       reservedKeys(cfg),
+		keyLayout:          cfg.keyLayout,
+
+		clock: cfg.clock,
+	}
+}
+
+func (p *pipeline) rewriteMessage(msg string) string {
+	return p.sanitizeString(p.scrubber.scrub(msg), p.maxMessageLength)
+}
+
+func (p *pipeline) rewriteString(value string) string {
+	return p.sanitizeString(p.scrubber.scrub(value), p.maxValueLength)
+}
+
+func (p *pipeline) rewriteAttr(attr slog.Attr, state *rewriteState) slog.Attr {
+	key := attr.Key
+	attr.Key = p.sanitizeKey(key)
+	if p.isPseudonymized(key) {
+		value := attr.Value.Resolve()
+		if source, ok := pseudonymSource(value); ok {
+			if source != Redacted {
+				attr.Value = slog.StringValue(p.pseudonymize(source, state))
+			} else {
+				attr.Value = value
+			}
+			return attr
+		}
+	}
+	if p.isRedacted(key) {
+		attr.Value = slog.StringValue(Redacted)
+		return attr
+	}
+	attr.Value = p.rewriteValue(attr.Value, state)
+	return attr
+}
+
+func (p *pipeline) rewriteValue(value slog.Value, state *rewriteState) slog.Value {
+	value = value.Resolve()
+	switch value.Kind() {
+	case slog.KindGroup:
+		return slog.GroupValue(p.rewriteAttrs(value.Group(), state)...)
+	case slog.KindString:
+		return slog.StringValue(p.rewriteString(value.String()))
+	case slog.KindAny:
+		return slog.AnyValue(p.rewriteAny(value.Any(), 0, state))
+	default:
+		return value
+	}
+}
+
+func (p *pipeline) rewriteAttrs(attrs []slog.Attr, state *rewriteState) []slog.Attr {
+	rewritten := make([]slog.Attr, 0, len(attrs))
+	for _, attr := range attrs {
+		rewritten = append(rewritten, p.rewriteAttr(attr, state))
+	}
+	return rewritten
+}
+
+func (p *pipeline) rewriteAny(value any, depth int, state *rewriteState) any {
+	if value = p.renderAny(value); value == nil {
+		return nil
+	}
+	if text, ok := value.(string); ok {
+		return p.rewriteString(text)
+	}
+
+	rv := reflect.ValueOf(value)
+	if rv.Type().Implements(jsonMarshalerType) {
+		return value
+	}
+	if depth >= p.maxDepth && isCompositeValue(rv) {
+		return DepthLimitMarker
+	}
+	if isStructValue(rv) {
+		return p.rewriteStruct(rv, depth, state)
+	}
+	if fields, ok := value.(Fields); ok {
+		result := make(Fields, len(fields))
+		for key, item := range fields {
+			result[p.sanitizeKey(key)] = p.rewriteField(key, item, depth, state)
+		}
+		return result
+	}
+
+	switch rv.Kind() {
+	case reflect.Map:
+		if rv.IsNil() || rv.Type().Key().Kind() != reflect.String {
+			return value
+		}
+		result := make(Fields, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			key := iter.Key().String()
+			result[p.sanitizeKey(key)] = p.rewriteField(key, iter.Value().Interface(), depth, state)
+		}
+		return result
+	case reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice && rv.IsNil() || !p.mayRewrite(rv.Type().Elem()) {
+			return value
+		}
+		result := make([]any, rv.Len())
+		for i := range result {
+			result[i] = p.rewriteAny(rv.Index(i).Interface(), depth+1, state)
+		}
+		return result
+	default:
+		return value
+	}
+}
+
+func (p *pipeline) renderAny(value any) any {
+	if isNilPointer(value) {
+		return nil
+	}
+	if valuer, ok := value.(slog.LogValuer); ok {
+		if value = resolvedAny(slog.AnyValue(valuer).Resolve()); isNilPointer(value) {
+			return nil
+		}
+	}
+	switch typed := value.(type) {
+	case nil, string, Fields, json.Marshaler:
+		return value
+	case time.Duration:
+		return encodeDuration(typed, p.durationEncoding).Any()
+	case error:
+		return typed.Error()
+	case encoding.TextMarshaler:
+		text, err := typed.MarshalText()
+		if err != nil {
+			return "!ERROR:" + err.Error()
+		}
+		return string(text)
+	case fmt.Stringer:
+		return typed.String()
+	default:
+		return value
+	}
+}
+
+func isNilPointer(value any) bool {
+	rv := reflect.ValueOf(value)
+	return rv.Kind() == reflect.Pointer && rv.IsNil()
+}
+
+func resolvedAny(value slog.Value) any {
+	if value.Kind() != slog.KindGroup {
+		return value.Any()
+	}
+	fields := Fields{}
+	for _, attr := range value.Group() {
+		addFieldAttr(fields, attr)
+	}
+	return fields
+}
+
+func (p *pipeline) rewriteField(key string, value any, depth int, state *rewriteState) any {
+	if p.isPseudonymized(key) {
+		if source, ok := anyPseudonymSource(value); ok && source != Redacted {
+			return p.pseudonymize(source, state)
+		}
+	}
+	if p.isRedacted(key) {
+		return Redacted
+	}
+	return p.rewriteAny(value, depth+1, state)
+}
+
+func (p *pipeline) mayRewrite(elem reflect.Type) bool {
+	if elem.Implements(logValuerType) || elem.Implements(stringerType) || elem.Implements(textMarshalerType) {
+		return !elem.Implements(jsonMarshalerType)
+	}
+	switch elem.Kind() {
+	case reflect.Struct:
+		return true
+	case reflect.Pointer:
+		return elem.Elem().Kind() == reflect.Struct
+	case reflect.Interface, reflect.Map, reflect.Slice, reflect.Array:
+		return elem.Kind() != reflect.Slice || elem.Elem().Kind() != reflect.Uint8
+	case reflect.String:
+		return p.scrubber != nil || p.sanitizesStrings()
+	default:
+		return false
+	}
+}
+
+type pipelineHandler struct {
+	root     slog.Handler
+	inner    slog.Handler
+	pipeline *pipeline
+
+	pseudonymVersion string
+	name             string
+	frames           [][]slog.Attr
+	groups           []string
+	taken            map[string]struct{}
+	merged           bool
+}
+
+func newPipelineHandler(inner slog.Handler, p *pipeline) slog.Handler {
+	return &pipelineHandler{
+		root:     inner,
+		inner:    inner,
+		pipeline: p,
+		frames:   make([][]slog.Attr, 1),
+		merged:   p.keyLayout != KeyLayoutAsIs,
+	}
+}
+
+func (h *pipelineHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.root.Enabled(ctx, level)
+}
+
+func (h *pipelineHandler) Handle(ctx context.Context, record slog.Record) error {
+	if !record.Time.IsZero() {
+		record.Time = h.pipeline.clock()
+	}
+	return h.handle(ctx, record)
+}
+
+func handleRecord(ctx context.Context, handler slog.Handler, record slog.Record) error {
+	if pipeline, ok := handler.(*pipelineHandler); ok {
+		return pipeline.handle(ctx, record)
+	}
+	return handler.Handle(ctx, record)
+}
+
+func (h *pipelineHandler) handle(ctx context.Context, record slog.Record) error {
+	state := rewriteState{pseudonymVersion: h.pseudonymVersion}
+	attrs := make([]slog.Attr, 0, record.NumAttrs()+1)
+	hasVersion := false
+	record.Attrs(func(attr slog.Attr) bool {
+		hasVersion = hasVersion || attr.Key == PseudonymVersionKey
+		attrs = append(attrs, h.pipeline.rewriteAttr(attr, &state))
+		return true
+	})
+	var trailing slog.Attr
+	if state.pseudonymVersion != "" && !hasVersion {
+		version := slog.String(PseudonymVersionKey, state.pseudonymVersion)
+		if len(h.groups) == 0 {
+			attrs = append(attrs, version)
+		} else {
+			trailing = version
+		}
+	}
+
+	inner := h.inner
+	merge := h.merged || trailing.Key != "" || h.pipeline.keysConflict(attrs, h.taken, len(h.groups) == 0)
+	if merge {
+		attrs = h.nest(attrs)
+		if trailing.Key != "" {
+			attrs = append(attrs, trailing)
+		}
+		inner, attrs = h.root, h.pipeline.resolveKeys(h.pipeline.layoutAttrs(attrs), true)
+	}
+
+	rewritten := slog.NewRecord(record.Time, record.Level, h.pipeline.rewriteMessage(record.Message), record.PC)
+	rewritten.AddAttrs(attrs...)
+	err := inner.Handle(ctx, rewritten)
+	h.pipeline.metrics.countRecord(h.name, Level(record.Level), recordAppCode(record))
+
+	if hooks := h.pipeline.hooks.matching(Level(record.Level)); len(hooks) > 0 {
+		if !merge {
+			attrs = h.nest(attrs)
+		}
+		hookRecord := buildHookRecord(rewritten, attrs)
+		for _, hook := range hooks {
+			hook.dispatch(ctx, hookRecord)
+		}
+	}
+	return err
+}
+
+func (h *pipelineHandler) nest(attrs []slog.Attr) []slog.Attr {
+	for i := len(h.groups) - 1; i >= 0; i-- {
+		attrs = append(slices.Clip(h.frames[i+1]), attrs...)
+		if len(attrs) > 0 {
+			attrs = []slog.Attr{{Key: h.groups[i], Value: slog.GroupValue(attrs...)}}
+		}
+	}
+	return append(slices.Clip(h.frames[0]), attrs...)
+}
+
+func (h *pipelineHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	state := rewriteState{pseudonymVersion: h.pseudonymVersion}
+	rewritten := h.pipeline.rewriteAttrs(attrs, &state)
+	child := h.clone()
+	child.pseudonymVersion = state.pseudonymVersion
+	last := len(child.frames) - 1
+	child.frames[last] = append(slices.Clip(child.frames[last]), rewritten...)
+	if len(h.groups) == 0 {
+		for _, attr := range rewritten {
+			if attr.Key == LoggerNameKey && attr.Value.Kind() == slog.KindString {
+				child.name = attr.Value.String()
+			}
+		}
+	}
+
+	if h.pipeline.keysConflict(child.frames[last], nil, len(h.groups) == 0) {
+		child.merged = true
+	}
+	if !child.merged {
+		child.inner = h.inner.WithAttrs(rewritten)
+		child.taken = make(map[string]struct{}, len(child.frames[last]))
+		for _, attr := range child.frames[last] {
+			child.taken[attr.Key] = struct{}{}
+		}
+	}
+	return child
+}
+
+func (h *pipelineHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	name = h.pipeline.sanitizeKey(name)
+	child := h.clone()
+	child.groups = append(child.groups, name)
+	child.frames = append(child.frames, nil)
+	child.taken = nil
+
+	_, taken := h.taken[name]
+	_, reserved := h.pipeline.reservedKeys[name]
+	if taken || reserved && len(h.groups) == 0 {
+		child.merged = true
+	}
+	if !child.merged {
+		child.inner = h.inner.WithGroup(name)
+	}
+	return child
+}
+
+func (h *pipelineHandler) clone() *pipelineHandler {
+	return &pipelineHandler{
+		root:             h.root,
+		inner:            h.inner,
+		pipeline:         h.pipeline,
+		pseudonymVersion: h.pseudonymVersion,
+		name:             h.name,
+		frames:           slices.Clone(h.frames),
+		groups:           slices.Clone(h.groups),
+		taken:            h.taken,
+		merged:           h.merged,
+	}
+}
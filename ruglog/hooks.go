@@ -0,0 +1,270 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var ErrHookQueueFull = errors.New("logger hook queue is full")
+
+type Record struct {
+	Time    time.Time
+	Level   Level
+	Message string
+	Fields  Fields
+}
+
+type HookOption func(*hookConfig)
+
+type hookConfig struct {
+	async     bool
+	queueSize int
+	onError   func(error)
+}
+
+func WithAsyncHook(queueSize int) HookOption {
+	return func(cfg *hookConfig) {
+		cfg.async = true
+		if queueSize > 0 {
+			cfg.queueSize = queueSize
+		}
+	}
+}
+
+func WithHookErrorHandler(fn func(error)) HookOption {
+	return func(cfg *hookConfig) {
+		cfg.onError = fn
+	}
+}
+
+type HookPanicError struct {
+	Value any
+	Stack []byte
+}
+
+func (e *HookPanicError) Error() string {
+	return fmt.Sprintf("logger hook panicked: %v", e.Value)
+}
+
+type hook struct {
+	minLevel Level
+	fn       func(context.Context, Record)
+	cfg      hookConfig
+
+	mu      sync.RWMutex
+	queue   chan hookCall
+	done    chan struct{}
+	stopped bool
+}
+
+type hookCall struct {
+	ctx     context.Context
+	record  Record
+	drained chan struct{}
+}
+
+type hookRegistry struct {
+	mu    sync.Mutex
+	hooks atomic.Pointer[[]*hook]
+}
+
+func (l *Logger) OnRecord(minLevel Level, fn func(context.Context, Record), opts ...HookOption) func() {
+	log := l.effective()
+	if fn == nil {
+		return func() {}
+	}
+
+	cfg := hookConfig{queueSize: 1024}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&cfg)
+		}
+	}
+
+	h := &hook{minLevel: minLevel, fn: fn, cfg: cfg}
+	if cfg.async {
+		h.queue = make(chan hookCall, cfg.queueSize)
+		h.done = make(chan struct{})
+		go h.loop()
+	}
+
+	registry := log.state.hooks
+	registry.mu.Lock()
+	current := registry.snapshot()
+	next := append(append(make([]*hook, 0, len(current)+1), current...), h)
+	registry.hooks.Store(&next)
+	registry.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			registry.remove(h)
+			h.stop()
+		})
+	}
+}
+
+func (r *hookRegistry) snapshot() []*hook {
+	if hooks := r.hooks.Load(); hooks != nil {
+		return *hooks
+	}
+	return nil
+}
+
+func (r *hookRegistry) remove(target *hook) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current := r.snapshot()
+	next := make([]*hook, 0, len(current))
+	for _, h := range current {
+		if h != target {
+			next = append(next, h)
+		}
+	}
+	r.hooks.Store(&next)
+}
+
+func (r *hookRegistry) close() {
+	r.mu.Lock()
+	hooks := r.snapshot()
+	r.hooks.Store(nil)
+	r.mu.Unlock()
+
+	for _, h := range hooks {
+		h.stop()
+	}
+}
+
+func (r *hookRegistry) drain() {
+	for _, h := range r.snapshot() {
+		h.drain()
+	}
+}
+
+func (r *hookRegistry) matching(level Level) []*hook {
+	if r == nil {
+		return nil
+	}
+	var matched []*hook
+	for _, h := range r.snapshot() {
+		if level >= h.minLevel {
+			matched = append(matched, h)
+		}
+	}
+	return matched
+}
+
+func (h *hook) dispatch(ctx context.Context, record Record) {
+	if !h.cfg.async {
+		h.call(ctx, record)
+		return
+	}
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if h.stopped {
+		return
+	}
+	select {
+	case h.queue <- hookCall{ctx: ctx, record: record}:
+	default:
+		h.fail(ErrHookQueueFull)
+	}
+}
+
+func (h *hook) call(ctx context.Context, record Record) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			h.fail(&HookPanicError{Value: recovered, Stack: debug.Stack()})
+		}
+	}()
+	h.fn(ctx, record)
+}
+
+func (h *hook) fail(err error) {
+	if h.cfg.onError != nil {
+		h.cfg.onError(err)
+	}
+}
+
+func (h *hook) loop() {
+	defer close(h.done)
+	for call := range h.queue {
+		if call.drained != nil {
+			close(call.drained)
+			continue
+		}
+		h.call(call.ctx, call.record)
+	}
+}
+
+func (h *hook) drain() {
+	if !h.cfg.async {
+		return
+	}
+	h.mu.RLock()
+	if h.stopped {
+		h.mu.RUnlock()
+		return
+	}
+	drained := make(chan struct{})
+	h.queue <- hookCall{drained: drained}
+	h.mu.RUnlock()
+	<-drained
+}
+
+func (h *hook) stop() {
+	if !h.cfg.async {
+		return
+	}
+	h.mu.Lock()
+	if h.stopped {
+		h.mu.Unlock()
+		return
+	}
+	h.stopped = true
+	close(h.queue)
+	h.mu.Unlock()
+	<-h.done
+}
+
+func buildHookRecord(record slog.Record, attrs []slog.Attr) Record {
+	fields := make(Fields, len(attrs))
+	for _, attr := range attrs {
+		addFieldAttr(fields, attr)
+	}
+
+	return Record{
+		Time:    record.Time,
+		Level:   Level(record.Level),
+		Message: record.Message,
+		Fields:  fields,
+	}
+}
+
+func addFieldAttr(fields Fields, attr slog.Attr) {
+	value := attr.Value.Resolve()
+	if value.Kind() != slog.KindGroup {
+		fields[attr.Key] = value.Any()
+		return
+	}
+
+	target := fields
+	if attr.Key != "" {
+		nested, ok := fields[attr.Key].(Fields)
+		if !ok {
+			nested = Fields{}
+			fields[attr.Key] = nested
+		}
+		target = nested
+	}
+	for _, child := range value.Group() {
+		addFieldAttr(target, child)
+	}
+}
@@ -0,0 +1,99 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSyncHooksSeeMatchingRecordsWithInheritedFields(t *testing.T) {
+	var output bytes.Buffer
+	log := MustNew(WithOutput(&output), WithLevel(LevelDebug), WithField("service", "billing"))
+
+	var records []Record
+	remove := log.OnRecord(LevelWarn, func(_ context.Context, record Record) {
+		records = append(records, record)
+	})
+
+	var panics []error
+	log.OnRecord(LevelError, func(context.Context, Record) {
+		panic("alerting is down")
+	}, WithHookErrorHandler(func(err error) {
+		panics = append(panics, err)
+	}))
+
+	worker := log.WithField("component", "worker").WithGroup("job")
+	worker.Info("started", nil)
+	worker.Warn("retrying", 7, Fields{"attempt": 2, "password": "hunter2"})
+	worker.Error("failed", errors.New("timeout"), 9, nil)
+
+	if len(records) != 2 {
+		t.Fatalf("expected WARN and ERROR records, got %+v", records)
+	}
+	warn := records[0]
+	job, _ := warn.Fields["job"].(Fields)
+	if warn.Level != LevelWarn || warn.Message != "retrying" || warn.Fields["service"] != "billing" || warn.Fields["component"] != "worker" {
+		t.Fatalf("unexpected hook record: %+v", warn)
+	}
+	if job["attempt"] != int64(2) || job["app_code"] != int64(7) || job["password"] != Redacted {
+		t.Fatalf("hook should see grouped and redacted fields: %+v", job)
+	}
+
+	var panicErr *HookPanicError
+	if len(panics) != 1 || !errors.As(panics[0], &panicErr) || panicErr.Value != "alerting is down" {
+		t.Fatalf("hook panic should be isolated and reported, got %v", panics)
+	}
+	if records := decodeRecords(t, output.String()); len(records) != 3 {
+		t.Fatalf("a panicking hook must not block logging, got %d records", len(records))
+	}
+
+	remove()
+	log.Error("after removal", nil, 0, nil)
+	if len(records) != 2 {
+		t.Fatalf("removed hook should not run, got %d records", len(records))
+	}
+}
+
+func TestAsyncHooksDrainOnClose(t *testing.T) {
+	log := MustNew(WithOutput(&bytes.Buffer{}))
+
+	var errorsSeen atomic.Int64
+	log.OnRecord(LevelError, func(context.Context, Record) {
+		errorsSeen.Add(1)
+	}, WithAsyncHook(100))
+
+	for i := 0; i < 50; i++ {
+		log.Error("boom", nil, 0, nil)
+		log.Info("noise", nil)
+	}
+	if err := log.Close(); err != nil {
+		t.Fatalf("close failed: %v", err)
+	}
+	if errorsSeen.Load() != 50 {
+		t.Fatalf("expected 50 async hook calls after close, got %d", errorsSeen.Load())
+	}
+}
+
+func TestAsyncHooksDrainBeforeFatalExit(t *testing.T) {
+	var calls atomic.Int32
+	var callsBeforeExit []int32
+	log := MustNew(WithOutput(&bytes.Buffer{}), WithExitFunc(func(int) {
+		callsBeforeExit = append(callsBeforeExit, calls.Load())
+	}))
+	defer log.Close()
+
+	log.OnRecord(LevelFatal, func(context.Context, Record) {
+		time.Sleep(10 * time.Millisecond)
+		calls.Add(1)
+	}, WithAsyncHook(16))
+
+	log.Fatal("cannot start", errors.New("boom"), 9001, nil)
+	log.Fatal("still cannot start", errors.New("boom"), 9001, nil)
+
+	if len(callsBeforeExit) != 2 || callsBeforeExit[0] != 1 || callsBeforeExit[1] != 2 {
+		t.Fatalf("async hooks must run before each exit and stay registered, got %v", callsBeforeExit)
+	}
+}
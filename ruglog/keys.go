@@ -0,0 +1,113 @@
+package logger
+
+import (
+	"fmt"
+	"log/slog"
+)
+
+const ConflictKeyPrefix = "fields."
+
+type DuplicateKeyPolicy int
+
+const (
+	DuplicateLastWins DuplicateKeyPolicy = iota
+	DuplicateFirstWins
+	DuplicatePrefix
+)
+
+func WithDuplicateKeyPolicy(policy DuplicateKeyPolicy) Option {
+	return func(cfg *config) error {
+		switch policy {
+		case DuplicateLastWins, DuplicateFirstWins, DuplicatePrefix:
+			cfg.duplicateKeys = policy
+			return nil
+		default:
+			return fmt.Errorf("unsupported duplicate key policy %d", policy)
+		}
+	}
+}
+
+func reservedKeys(cfg config) map[string]struct{} {
+	keys := map[string]struct{}{
+		slog.TimeKey:    {},
+		slog.LevelKey:   {},
+		slog.MessageKey: {},
+		"timestamp":     {},
+		"message":       {},
+	}
+	if cfg.addSource {
+		keys[slog.SourceKey] = struct{}{}
+	}
+	return keys
+}
+
+func (p *pipeline) resolveKeys(attrs []slog.Attr, top bool) []slog.Attr {
+	resolved := make([]slog.Attr, 0, len(attrs))
+	index := make(map[string]int, len(attrs))
+
+	var add func(slog.Attr)
+	add = func(attr slog.Attr) {
+		if attr.Equal(slog.Attr{}) {
+			return
+		}
+		if attr.Value.Kind() == slog.KindGroup {
+			if attr.Key == "" {
+				for _, child := range attr.Value.Group() {
+					add(child)
+				}
+				return
+			}
+			children := p.resolveKeys(attr.Value.Group(), false)
+			if len(children) == 0 {
+				return
+			}
+			attr.Value = slog.GroupValue(children...)
+		}
+		if _, ok := p.reservedKeys[attr.Key]; ok && top {
+			attr.Key = ConflictKeyPrefix + attr.Key
+		}
+
+		i, exists := index[attr.Key]
+		if exists && p.duplicateKeys == DuplicatePrefix {
+			attr.Key = ConflictKeyPrefix + attr.Key
+			i, exists = index[attr.Key]
+		}
+		switch {
+		case !exists:
+			index[attr.Key] = len(resolved)
+			resolved = append(resolved, attr)
+		case p.duplicateKeys == DuplicateFirstWins:
+		default:
+			resolved[i] = attr
+		}
+	}
+	for _, attr := range attrs {
+		add(attr)
+	}
+	return resolved
+}
+
+func (p *pipeline) keysConflict(attrs []slog.Attr, taken map[string]struct{}, top bool) bool {
+	for i, attr := range attrs {
+		if attr.Key == "" {
+			return true
+		}
+		if _, ok := taken[attr.Key]; ok {
+			return true
+		}
+		if _, ok := p.reservedKeys[attr.Key]; ok && top {
+			return true
+		}
+		for _, prev := range attrs[:i] {
+			if prev.Key == attr.Key {
+				return true
+			}
+		}
+		if attr.Value.Kind() == slog.KindGroup {
+			if group := attr.Value.Group(); len(group) == 0 || p.keysConflict(group, nil, false) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,115 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestDuplicateKeyPolicies(t *testing.T) {
+	cases := []struct {
+		policy   DuplicateKeyPolicy
+		expected map[string]any
+	}{
+		{DuplicateLastWins, map[string]any{"status": "done"}},
+		{DuplicateFirstWins, map[string]any{"status": "pending"}},
+		{DuplicatePrefix, map[string]any{"status": "pending", ConflictKeyPrefix + "status": "done"}},
+	}
+	for _, tc := range cases {
+		var output bytes.Buffer
+		log := MustNew(WithOutput(&output), WithDuplicateKeyPolicy(tc.policy), WithField("status", "pending"))
+		log.Info("job finished", Fields{"status": "done"})
+
+		line := strings.TrimSpace(output.String())
+		if strings.Count(line, `"status"`) != 1 {
+			t.Fatalf("policy %d produced duplicate keys: %s", tc.policy, line)
+		}
+		record := decodeRecords(t, line)[0]
+		for key, value := range tc.expected {
+			if record[key] != value {
+				t.Fatalf("policy %d: %q = %#v, want %#v in %s", tc.policy, key, record[key], value, line)
+			}
+		}
+	}
+}
+
+func TestReservedKeysAreMovedUnderPrefix(t *testing.T) {
+	var output bytes.Buffer
+	log := MustNew(WithOutput(&output), WithTimeFormat("2006"))
+	log.WithGroup("http").WithField("message", "nested ok").Info("request", Fields{"msg": "grouped"})
+	log.Info("created", Fields{"message": "user text", "timestamp": "yesterday", "time": 5, "level": "low"})
+
+	lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(lines[1]), &raw); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	for _, key := range []string{"message", "timestamp", "level"} {
+		if strings.Count(lines[1], `"`+key+`"`) != 1 {
+			t.Fatalf("key %q is ambiguous: %s", key, lines[1])
+		}
+	}
+
+	records := decodeRecords(t, output.String())
+	nested := records[0]["http"].(map[string]any)
+	if nested["message"] != "nested ok" || nested["msg"] != "grouped" {
+		t.Fatalf("keys inside groups must keep their names: %#v", nested)
+	}
+	created := records[1]
+	if created["message"] != "created" || created["level"] != "INFO" {
+		t.Fatalf("built-in keys were overwritten: %#v", created)
+	}
+	expected := map[string]any{"fields.message": "user text", "fields.timestamp": "yesterday", "fields.time": float64(5), "fields.level": "low"}
+	for key, value := range expected {
+		if created[key] != value {
+			t.Fatalf("%q = %#v, want %#v in %#v", key, created[key], value, created)
+		}
+	}
+}
+
+func TestContextFieldsStayUniqueAcrossWithCalls(t *testing.T) {
+	var output bytes.Buffer
+	log := MustNew(WithOutput(&output))
+	log.WithField("id", 1).WithField("id", 2).Info("rebound", nil)
+	log.WithGroup("http").WithField("path", "/a").Info("grouped", Fields{"path": "/b", "status": 200})
+	log.WithField("http", "plain").WithGroup("http").Info("shadowed", Fields{"path": "/c"})
+	log.WithField("message", "user text").Info("reserved", nil)
+	log.WithField("service", "billing").WithGroup("http").WithField("path", "/d").Info("clean", Fields{"status": 204})
+
+	lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+	for i, key := range []string{"id", "path", "http", "message", "path"} {
+		if strings.Count(lines[i], `"`+key+`"`) != 1 {
+			t.Fatalf("key %q is ambiguous: %s", key, lines[i])
+		}
+	}
+
+	records := decodeRecords(t, output.String())
+	if records[0]["id"] != float64(2) {
+		t.Fatalf("later With value must win: %#v", records[0])
+	}
+	if http := records[1]["http"].(map[string]any); http["path"] != "/b" || http["status"] != float64(200) {
+		t.Fatalf("record value must win inside the group: %#v", http)
+	}
+	if records[3]["message"] != "reserved" || records[3][ConflictKeyPrefix+"message"] != "user text" {
+		t.Fatalf("reserved With key must move under prefix: %#v", records[3])
+	}
+	clean := records[4]
+	if http := clean["http"].(map[string]any); clean["service"] != "billing" || http["path"] != "/d" || http["status"] != float64(204) {
+		t.Fatalf("unexpected fields without conflicts: %#v", clean)
+	}
+}
+
+func BenchmarkWithFields(b *testing.B) {
+	fields := Fields{}
+	for i := range 10 {
+		fields[fmt.Sprintf("field_%d", i)] = i
+	}
+	log := MustNew(WithOutput(io.Discard)).WithFields(fields)
+	b.ReportAllocs()
+	for b.Loop() {
+		log.Info("request served", Fields{"status": 200})
+	}
+}
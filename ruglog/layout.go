@@ -0,0 +1,167 @@
+package logger
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"sort"
+	"strings"
+)
+
+type KeyLayout int
+
+const (
+	KeyLayoutAsIs KeyLayout = iota
+	KeyLayoutNested
+	KeyLayoutFlat
+)
+
+func WithKeyLayout(layout KeyLayout) Option {
+	return func(cfg *config) error {
+		switch layout {
+		case KeyLayoutAsIs, KeyLayoutNested, KeyLayoutFlat:
+			cfg.keyLayout = layout
+			return nil
+		default:
+			return fmt.Errorf("unsupported key layout %d", layout)
+		}
+	}
+}
+
+func (p *pipeline) layoutAttrs(attrs []slog.Attr) []slog.Attr {
+	switch p.keyLayout {
+	case KeyLayoutNested:
+		return nestDottedKeys(attrs)
+	case KeyLayoutFlat:
+		return flattenAttrs(nil, "", attrs)
+	default:
+		return attrs
+	}
+}
+
+type keyNode struct {
+	key      string
+	leaf     *slog.Attr
+	children []*keyNode
+}
+
+func (n *keyNode) child(key string) *keyNode {
+	for _, child := range n.children {
+		if child.key == key && child.leaf == nil {
+			return child
+		}
+	}
+	child := &keyNode{key: key}
+	n.children = append(n.children, child)
+	return child
+}
+
+func (n *keyNode) hasLeaf(key string) bool {
+	for _, child := range n.children {
+		if child.key == key && child.leaf != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func (n *keyNode) insert(attr slog.Attr) {
+	if attr.Value.Kind() == slog.KindGroup {
+		target := n
+		if attr.Key != "" {
+			target = n.path(attr.Key)
+		}
+		if target == nil {
+			n.children = append(n.children, &keyNode{key: attr.Key, leaf: &attr})
+			return
+		}
+		for _, child := range attr.Value.Group() {
+			target.insert(child)
+		}
+		return
+	}
+
+	parts := strings.Split(attr.Key, ".")
+	target := n.path(strings.Join(parts[:len(parts)-1], "."))
+	if target == nil || len(parts) == 1 || slices.Contains(parts, "") {
+		n.children = append(n.children, &keyNode{key: attr.Key, leaf: &attr})
+		return
+	}
+	attr.Key = parts[len(parts)-1]
+	target.children = append(target.children, &keyNode{key: attr.Key, leaf: &attr})
+}
+
+func (n *keyNode) path(dotted string) *keyNode {
+	target := n
+	if dotted == "" {
+		return target
+	}
+	for _, part := range strings.Split(dotted, ".") {
+		if part == "" || target.hasLeaf(part) {
+			return nil
+		}
+		target = target.child(part)
+	}
+	return target
+}
+
+func (n *keyNode) attrs() []slog.Attr {
+	attrs := make([]slog.Attr, 0, len(n.children))
+	for _, child := range n.children {
+		if child.leaf != nil {
+			attrs = append(attrs, *child.leaf)
+			continue
+		}
+		if nested := child.attrs(); len(nested) > 0 {
+			attrs = append(attrs, slog.Attr{Key: child.key, Value: slog.GroupValue(nested...)})
+		}
+	}
+	return attrs
+}
+
+func nestDottedKeys(attrs []slog.Attr) []slog.Attr {
+	root := &keyNode{}
+	for _, attr := range attrs {
+		root.insert(attr)
+	}
+	return root.attrs()
+}
+
+func flattenAttrs(flat []slog.Attr, prefix string, attrs []slog.Attr) []slog.Attr {
+	for _, attr := range attrs {
+		key := attr.Key
+		if prefix != "" && key != "" {
+			key = prefix + "." + key
+		} else if key == "" {
+			key = prefix
+		}
+
+		switch attr.Value.Kind() {
+		case slog.KindGroup:
+			flat = flattenAttrs(flat, key, attr.Value.Group())
+		case slog.KindAny:
+			if fields, ok := attr.Value.Any().(Fields); ok && len(fields) > 0 {
+				flat = flattenAttrs(flat, key, fieldAttrs(fields))
+				continue
+			}
+			flat = append(flat, slog.Attr{Key: key, Value: attr.Value})
+		default:
+			flat = append(flat, slog.Attr{Key: key, Value: attr.Value})
+		}
+	}
+	return flat
+}
+
+func fieldAttrs(fields Fields) []slog.Attr {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	attrs := make([]slog.Attr, 0, len(keys))
+	for _, key := range keys {
+		attrs = append(attrs, slog.Any(key, fields[key]))
+	}
+	return attrs
+}
@@ -0,0 +1,53 @@
+package logger
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNestedKeyLayoutExpandsDottedKeys(t *testing.T) {
+	var output bytes.Buffer
+	log := MustNew(WithOutput(&output), WithKeyLayout(KeyLayoutNested), WithTimeFormat("2006"), WithClock(layoutClock))
+
+	log.WithGroup("http").WithField("route", "/orders").Info("request", Fields{
+		"http.method": "GET",
+		"user.id":     42,
+		"trace..id":   "t-1",
+	})
+
+	line := strings.TrimSpace(output.String())
+	expected := `{"timestamp":"2026","level":"INFO","message":"request","http":{"route":"/orders","http":{"method":"GET"},"trace..id":"t-1","user":{"id":42}}}`
+	if line != expected {
+		t.Fatalf("unexpected nested output:\n got %s\nwant %s", line, expected)
+	}
+
+	output.Reset()
+	log.WithField("http.method", "POST").Info("request", Fields{"http.status": 201, "db": Fields{"rows": 1}})
+	record := decodeRecords(t, output.String())[0]
+	http := record["http"].(map[string]any)
+	if http["method"] != "POST" || http["status"] != float64(201) {
+		t.Fatalf("dotted keys from With and the call must share one object: %#v", record)
+	}
+}
+
+func TestFlatKeyLayoutFlattensGroupsAndMaps(t *testing.T) {
+	var output bytes.Buffer
+	log := MustNew(WithOutput(&output), WithKeyLayout(KeyLayoutFlat), WithTimeFormat("2006"), WithClock(layoutClock))
+
+	log.WithGroup("http").WithField("method", "GET").Info("request", Fields{
+		"headers": Fields{"accept": "json", "cache": Fields{"hit": true}},
+		"tags":    []string{"a", "b"},
+	})
+
+	line := strings.TrimSpace(output.String())
+	expected := `{"timestamp":"2026","level":"INFO","message":"request","http.method":"GET","http.headers.accept":"json","http.headers.cache.hit":true,"http.tags":["a","b"]}`
+	if line != expected {
+		t.Fatalf("unexpected flat output:\n got %s\nwant %s", line, expected)
+	}
+}
+
+func layoutClock() time.Time {
+	return time.Date(2026, 5, 11, 13, 0, 0, 0, time.UTC)
+}
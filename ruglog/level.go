@@ -0,0 +1,139 @@
+package logger
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+type levelDefinition struct {
+	name   string
+	level  Level
+	syslog int
+}
+
+type levelTable struct {
+	byLevel map[Level]levelDefinition
+	byName  map[string]Level
+	ordered []Level
+}
+
+var (
+	levelsMu sync.Mutex
+	levels   atomic.Pointer[levelTable]
+)
+
+func init() {
+	table := &levelTable{byLevel: map[Level]levelDefinition{}, byName: map[string]Level{}}
+	for _, definition := range []levelDefinition{
+		{name: "TRACE", level: LevelTrace, syslog: 7},
+		{name: "DEBUG", level: LevelDebug, syslog: 7},
+		{name: "INFO", level: LevelInfo, syslog: 6},
+		{name: "WARN", level: LevelWarn, syslog: 4},
+		{name: "ERROR", level: LevelError, syslog: 3},
+		{name: "PANIC", level: LevelPanic, syslog: 2},
+		{name: "FATAL", level: LevelFatal, syslog: 2},
+	} {
+		table = table.with(definition)
+	}
+	table.byName["warning"] = LevelWarn
+	levels.Store(table)
+}
+
+func RegisterLevel(name string, level Level, syslogSeverity int) error {
+	name = strings.ToUpper(strings.TrimSpace(name))
+	if name == "" || strings.ContainsAny(name, " \t+-") {
+		return fmt.Errorf("invalid log level name %q", name)
+	}
+	if syslogSeverity < 0 || syslogSeverity > 7 {
+		return fmt.Errorf("log level %s: syslog severity %d is out of range 0..7", name, syslogSeverity)
+	}
+
+	levelsMu.Lock()
+	defer levelsMu.Unlock()
+
+	table := levels.Load()
+	definition := levelDefinition{name: name, level: level, syslog: syslogSeverity}
+	if existing, ok := table.byLevel[level]; ok {
+		if existing == definition {
+			return nil
+		}
+		return fmt.Errorf("log level %d is already registered as %s", int(level), existing.name)
+	}
+	if existing, ok := table.byName[strings.ToLower(name)]; ok {
+		return fmt.Errorf("log level %s is already registered with severity %d", name, int(existing))
+	}
+	levels.Store(table.with(definition))
+	return nil
+}
+
+func (t *levelTable) with(definition levelDefinition) *levelTable {
+	next := &levelTable{
+		byLevel: make(map[Level]levelDefinition, len(t.byLevel)+1),
+		byName:  make(map[string]Level, len(t.byName)+1),
+		ordered: append(make([]Level, 0, len(t.ordered)+1), t.ordered...),
+	}
+	for level, existing := range t.byLevel {
+		next.byLevel[level] = existing
+	}
+	for name, level := range t.byName {
+		next.byName[name] = level
+	}
+	next.byLevel[definition.level] = definition
+	next.byName[strings.ToLower(definition.name)] = definition.level
+	next.ordered = append(next.ordered, definition.level)
+	sort.Slice(next.ordered, func(i, j int) bool { return next.ordered[i] < next.ordered[j] })
+	return next
+}
+
+func (t *levelTable) nearest(level Level) levelDefinition {
+	index := sort.Search(len(t.ordered), func(i int) bool { return t.ordered[i] > level })
+	if index > 0 {
+		index--
+	}
+	return t.byLevel[t.ordered[index]]
+}
+
+func (l Level) String() string {
+	table := levels.Load()
+	if definition, ok := table.byLevel[l]; ok {
+		return definition.name
+	}
+	definition := table.nearest(l)
+	delta := int(l - definition.level)
+	if delta > 0 {
+		return definition.name + "+" + strconv.Itoa(delta)
+	}
+	return definition.name + strconv.Itoa(delta)
+}
+
+func (l Level) SyslogSeverity() int {
+	return levels.Load().nearest(l).syslog
+}
+
+func (l Level) OTLPSeverity() int {
+	return min(max(int(l)+9, 1), 24)
+}
+
+func ParseLevel(value string) (Level, error) {
+	normalized := strings.ToLower(strings.TrimSpace(value))
+	if normalized == "" {
+		return LevelInfo, nil
+	}
+
+	table := levels.Load()
+	if level, ok := table.byName[normalized]; ok {
+		return level, nil
+	}
+	if index := strings.LastIndexAny(normalized, "+-"); index > 0 {
+		base, ok := table.byName[normalized[:index]]
+		delta, err := strconv.Atoi(normalized[index:])
+		if ok && err == nil {
+			return base + Level(delta), nil
+		}
+	}
+	return LevelInfo, fmt.Errorf("unknown log level %q", value)
+}
@@ -0,0 +1,67 @@
+package logger
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRegisteredLevelsAreRenderedParsedAndMapped(t *testing.T) {
+	const (
+		levelNotice   Level = 2
+		levelCritical Level = 10
+	)
+	if err := RegisterLevel("notice", levelNotice, 5); err != nil {
+		t.Fatalf("register notice: %v", err)
+	}
+	if err := RegisterLevel("CRITICAL", levelCritical, 2); err != nil {
+		t.Fatalf("register critical: %v", err)
+	}
+	if err := RegisterLevel("NOTICE", levelNotice, 5); err != nil {
+		t.Fatalf("repeated identical registration must succeed: %v", err)
+	}
+	if err := RegisterLevel("ALERT", levelNotice, 1); err == nil {
+		t.Fatalf("expected an error for a taken severity")
+	}
+	if err := RegisterLevel("info", 1, 6); err == nil {
+		t.Fatalf("expected an error for a taken name")
+	}
+
+	var output bytes.Buffer
+	log := MustNew(WithOutput(&output), WithLevel(LevelTrace))
+	log.Trace("trace", nil)
+	log.Log(levelNotice, "notice", nil, 0, nil)
+	log.Log(levelCritical, "critical", nil, 0, nil)
+	log.Log(LevelFatal+1, "beyond fatal", nil, 0, nil)
+
+	var levels []string
+	for _, record := range decodeRecords(t, output.String()) {
+		levels = append(levels, record["level"].(string))
+	}
+	if strings.Join(levels, ",") != "TRACE,NOTICE,CRITICAL,FATAL+1" {
+		t.Fatalf("unexpected rendered levels: %v", levels)
+	}
+
+	for value, expected := range map[string]Level{"Notice": levelNotice, "critical": levelCritical, "WARNING": LevelWarn, "FATAL+1": LevelFatal + 1, "INFO-1": LevelInfo - 1} {
+		level, err := ParseLevel(value)
+		if err != nil || level != expected {
+			t.Fatalf("ParseLevel(%q) = %v, %v; want %v", value, level, err, expected)
+		}
+	}
+	if _, err := ParseLevel("notice+x"); err == nil {
+		t.Fatalf("expected an error for a malformed offset")
+	}
+
+	for level, expected := range map[Level][2]int{
+		LevelTrace:    {7, 1},
+		LevelInfo:     {6, 9},
+		levelNotice:   {5, 11},
+		LevelWarn:     {4, 13},
+		levelCritical: {2, 19},
+		LevelFatal:    {2, 21},
+	} {
+		if got := [2]int{level.SyslogSeverity(), level.OTLPSeverity()}; got != expected {
+			t.Fatalf("%s severities = %v, want %v", level, got, expected)
+		}
+	}
+}
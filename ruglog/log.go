@@ -8,12 +8,16 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
+	"runtime"
 	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
+const packagePath = "github.com/PrototypeSirius/ruglogger/ruglog"
+
 type Level int
 
 const (
@@ -22,47 +26,10 @@ const (
 	LevelInfo  Level = Level(slog.LevelInfo)
 	LevelWarn  Level = Level(slog.LevelWarn)
 	LevelError Level = Level(slog.LevelError)
+	LevelPanic Level = 11
 	LevelFatal Level = 12
 )
 
-func (l Level) String() string {
-	switch l {
-	case LevelTrace:
-		return "TRACE"
-	case LevelDebug:
-		return "DEBUG"
-	case LevelInfo:
-		return "INFO"
-	case LevelWarn:
-		return "WARN"
-	case LevelError:
-		return "ERROR"
-	case LevelFatal:
-		return "FATAL"
-	default:
-		return fmt.Sprintf("LEVEL(%d)", int(l))
-	}
-}
-
-func ParseLevel(value string) (Level, error) {
-	switch strings.ToLower(strings.TrimSpace(value)) {
-	case "trace":
-		return LevelTrace, nil
-	case "debug":
-		return LevelDebug, nil
-	case "info", "":
-		return LevelInfo, nil
-	case "warn", "warning":
-		return LevelWarn, nil
-	case "error":
-		return LevelError, nil
-	case "fatal":
-		return LevelFatal, nil
-	default:
-		return LevelInfo, fmt.Errorf("unknown log level %q", value)
-	}
-}
-
 type Format string
 
 const (
@@ -85,16 +52,47 @@ type config struct {
 	replaceAttr func([]string, slog.Attr) slog.Attr
 	handler     slog.Handler
 	exitFunc    func(int)
+	clock       func() time.Time
+
+	location          *time.Location
+	timestampEncoding TimestampEncoding
+	durationEncoding  DurationEncoding
+	maxDepth          int
+	duplicateKeys     DuplicateKeyPolicy
+	keyLayout         KeyLayout
+	redactedKeys      map[string]struct{}
+	scrubModes        map[ScrubPattern]ScrubMode
+	pseudonymizer     *Pseudonymizer
+	pseudonymizedKeys map[string]struct{}
+
+	escapeControlChars bool
+	maxMessageLength   int
+	maxValueLength     int
+
+	backtraceSize  int
+	backtraceLevel Level
+
+	fallback      io.Writer
+	fallbackRetry time.Duration
+	onWriteError  func(error)
 }
 
 func defaultConfig() config {
 	return config{
-		level:      LevelInfo,
-		format:     FormatJSON,
-		timeFormat: time.RFC3339Nano,
-		outputs:    []io.Writer{os.Stdout},
-		defaults:   Fields{},
-		exitFunc:   os.Exit,
+		level:             LevelInfo,
+		format:            FormatJSON,
+		timeFormat:        time.RFC3339Nano,
+		outputs:           []io.Writer{os.Stdout},
+		defaults:          Fields{},
+		exitFunc:          os.Exit,
+		clock:             time.Now,
+		timestampEncoding: TimestampLayout,
+		durationEncoding:  DurationNative,
+		maxDepth:          32,
+		redactedKeys:      mergeKeySets(defaultRedactedKeys),
+
+		escapeControlChars: true,
+		backtraceLevel:     LevelTrace,
 	}
 }
 
@@ -188,6 +186,17 @@ func WithFile(path string) Option {
 	}
 }
 
+func WithSink(sink io.WriteCloser) Option {
+	return func(cfg *config) error {
+		if sink == nil {
+			return errors.New("logger sink cannot be nil")
+		}
+		cfg.outputs = append(cfg.outputs, sink)
+		cfg.closers = append(cfg.closers, sink)
+		return nil
+	}
+}
+
 func WithFields(fields Fields) Option {
 	return func(cfg *config) error {
 		cfg.defaults = MergeFields(cfg.defaults, fields)
@@ -226,17 +235,40 @@ func WithExitFunc(fn func(int)) Option {
 	}
 }
 
+func WithClock(clock func() time.Time) Option {
+	return func(cfg *config) error {
+		if clock == nil {
+			return errors.New("logger clock cannot be nil")
+		}
+		cfg.clock = clock
+		return nil
+	}
+}
+
 type sharedState struct {
 	closeOnce sync.Once
 	closeErr  error
 	closers   []io.Closer
+	sinks     []*BatchWriter
 	exitFunc  func(int)
 	level     *slog.LevelVar
+	clock     func() time.Time
+
+	writeErrors  atomic.Uint64
+	onWriteError func(error)
+
+	backtrace      *backtraceRing
+	backtraceLevel Level
+
+	hooks   *hookRegistry
+	metrics *metrics
 }
 
 type Logger struct {
-	base  *slog.Logger
-	state *sharedState
+	base   *slog.Logger
+	state  *sharedState
+	buffer *RecordBuffer
+	name   string
 }
 
 func New(opts ...Option) (*Logger, error) {
@@ -252,20 +284,35 @@ func New(opts ...Option) (*Logger, error) {
 	}
 
 	state := &sharedState{
-		closers:  cfg.closers,
-		exitFunc: cfg.exitFunc,
-		level:    &slog.LevelVar{},
+		closers:      cfg.closers,
+		exitFunc:     cfg.exitFunc,
+		level:        &slog.LevelVar{},
+		clock:        cfg.clock,
+		onWriteError: cfg.onWriteError,
+
+		backtraceLevel: cfg.backtraceLevel,
+
+		hooks:   &hookRegistry{},
+		metrics: &metrics{},
+	}
+	for _, output := range append(cfg.outputs[:len(cfg.outputs):len(cfg.outputs)], cfg.fallback) {
+		if sink, ok := output.(*BatchWriter); ok {
+			state.sinks = append(state.sinks, sink)
+		}
+	}
+	if cfg.backtraceSize > 0 {
+		state.backtrace = newBacktraceRing(cfg.backtraceSize)
 	}
 	state.level.Set(slog.Level(cfg.level))
 
 	handler := cfg.handler
 	if handler == nil {
-		writer := resolveWriter(cfg.outputs)
+		writer := resolveWriter(cfg, state)
 		options := &slog.HandlerOptions{
 			Level:     state.level,
 			AddSource: cfg.addSource,
 			ReplaceAttr: composeReplaceAttr(
-				defaultReplaceAttr(cfg.timeFormat),
+				defaultReplaceAttr(cfg),
 				cfg.replaceAttr,
 			),
 		}
@@ -281,14 +328,19 @@ func New(opts ...Option) (*Logger, error) {
 		}
 	}
 
-	base := slog.New(handler)
+	processing := newPipeline(cfg)
+	processing.hooks = state.hooks
+	processing.metrics = state.metrics
+	base := slog.New(newPipelineHandler(handler, processing))
 	if len(cfg.defaults) > 0 {
 		base = base.With(fieldsToArgs(cfg.defaults)...)
 	}
 
+	name, _ := cfg.defaults[LoggerNameKey].(string)
 	return &Logger{
 		base:  base,
 		state: state,
+		name:  name,
 	}, nil
 }
 
@@ -302,8 +354,10 @@ func MustNew(opts ...Option) *Logger {
 
 func (l *Logger) clone(base *slog.Logger) *Logger {
 	return &Logger{
-		base:  base,
-		state: l.state,
+		base:   base,
+		state:  l.state,
+		buffer: l.buffer,
+		name:   l.name,
 	}
 }
 
@@ -323,11 +377,16 @@ func (l *Logger) Close() error {
 		return nil
 	}
 	l.state.closeOnce.Do(func() {
+		l.state.hooks.close()
 		l.state.closeErr = closeAll(l.state.closers)
 	})
 	return l.state.closeErr
 }
 
+func (l *Logger) Now() time.Time {
+	return l.effective().state.clock()
+}
+
 func (l *Logger) SetLevel(level Level) {
 	l.effective().state.level.Set(slog.Level(level))
 }
@@ -407,8 +466,32 @@ func (l *Logger) LogContext(ctx context.Context, level Level, msg string, err er
 		merged["app_code"] = appCode
 	}
 
-	log.base.Log(ctx, slog.Level(level), msg, fieldsToArgs(merged)...)
+	enabled := log.base.Enabled(ctx, slog.Level(level))
+	if !enabled && log.buffer.holds(level) {
+		record := slog.NewRecord(log.state.clock(), slog.Level(level), msg, callerPC())
+		record.Add(fieldsToArgs(merged)...)
+		log.buffer.add(log, record)
+		return
+	}
+
+	ring := log.backtraceRing(ctx)
+	if enabled || ring != nil && level >= log.state.backtraceLevel {
+		record := slog.NewRecord(log.state.clock(), slog.Level(level), msg, callerPC())
+		record.Add(fieldsToArgs(merged)...)
+		if !enabled {
+			ring.push(log.base.Handler(), record)
+		} else {
+			if ring != nil && level >= LevelError {
+				log.dumpBacktrace(ctx, ring)
+			}
+			if err := handleRecord(ctx, log.base.Handler(), record); err != nil {
+				log.state.reportWriteError(err)
+			}
+		}
+	}
 	if level == LevelFatal {
+		log.state.hooks.drain()
+		log.state.flushSinks()
 		log.state.exitFunc(1)
 	}
 }
@@ -453,6 +536,18 @@ func (l *Logger) ErrorContext(ctx context.Context, msg string, err error, appCod
 	l.LogContext(ctx, LevelError, msg, err, appCode, fields)
 }
 
+func (l *Logger) Panic(msg string, err error, appCode int, fields Fields) {
+	l.PanicContext(context.Background(), msg, err, appCode, fields)
+}
+
+func (l *Logger) PanicContext(ctx context.Context, msg string, err error, appCode int, fields Fields) {
+	l.LogContext(ctx, LevelPanic, msg, err, appCode, fields)
+	if err == nil {
+		err = errors.New(msg)
+	}
+	panic(err)
+}
+
 func (l *Logger) Fatal(msg string, err error, appCode int, fields Fields) {
 	l.Log(LevelFatal, msg, err, appCode, fields)
 }
@@ -496,28 +591,81 @@ func normalizeFieldValue(value any) any {
 	return value
 }
 
-func resolveWriter(outputs []io.Writer) io.Writer {
-	switch len(outputs) {
-	case 0:
-		return os.Stdout
-	case 1:
-		return outputs[0]
-	default:
-		return io.MultiWriter(outputs...)
+func resolveWriter(cfg config, state *sharedState) io.Writer {
+	outputs := cfg.outputs
+	if len(outputs) == 0 {
+		outputs = []io.Writer{os.Stdout}
+	}
+
+	names := outputNames(outputs)
+	writers := make(fanoutWriter, 0, len(outputs))
+	for i, output := range outputs {
+		writer := &outputWriter{
+			primary:       output,
+			fallback:      cfg.fallback,
+			retryInterval: cfg.fallbackRetry,
+			state:         state,
+			metrics:       &outputMetrics{name: names[i]},
+		}
+		state.metrics.outputs = append(state.metrics.outputs, writer)
+		writers = append(writers, writer)
+	}
+	if len(writers) == 1 {
+		return writers[0]
 	}
+	return writers
 }
 
-func defaultReplaceAttr(timeFormat string) func([]string, slog.Attr) slog.Attr {
-	return func(_ []string, attr slog.Attr) slog.Attr {
+func callerPC() uintptr {
+	var pcs [16]uintptr
+	count := runtime.Callers(2, pcs[:])
+	frames := runtime.CallersFrames(pcs[:count])
+	skip := 2
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, packagePath+".") {
+			break
+		}
+		skip++
+		if !more {
+			return 0
+		}
+	}
+
+	var pc [1]uintptr
+	if runtime.Callers(skip, pc[:]) == 0 {
+		return 0
+	}
+	return pc[0]
+}
+
+func defaultReplaceAttr(cfg config) func([]string, slog.Attr) slog.Attr {
+	return func(groups []string, attr slog.Attr) slog.Attr {
+		switch attr.Value.Kind() {
+		case slog.KindDuration:
+			attr.Value = encodeDuration(attr.Value.Duration(), cfg.durationEncoding)
+		case slog.KindTime:
+			if cfg.location != nil && attr.Key != slog.TimeKey {
+				attr.Value = slog.TimeValue(attr.Value.Time().In(cfg.location))
+			}
+		}
+
+		if len(groups) > 0 {
+			return attr
+		}
 		switch attr.Key {
 		case slog.TimeKey:
 			attr.Key = "timestamp"
 			if attr.Value.Kind() == slog.KindTime {
-				attr.Value = slog.StringValue(attr.Value.Time().Format(timeFormat))
+				attr.Value = encodeTimestamp(attr.Value.Time(), cfg)
 			}
 		case slog.LevelKey:
 			attr.Key = "level"
-			attr.Value = slog.StringValue(strings.ToUpper(attr.Value.String()))
+			if level, ok := attr.Value.Any().(slog.Level); ok {
+				attr.Value = slog.StringValue(Level(level).String())
+			} else {
+				attr.Value = slog.StringValue(strings.ToUpper(attr.Value.String()))
+			}
 		case slog.MessageKey:
 			attr.Key = "message"
 		}
@@ -675,6 +823,10 @@ func Error(msg string, err error, appCode int, fields Fields) {
 	Get().Error(msg, err, appCode, fields)
 }
 
+func Panic(msg string, err error, appCode int, fields Fields) {
+	Get().Panic(msg, err, appCode, fields)
+}
+
 func Fatal(msg string, err error, appCode int, fields Fields) {
 	Get().Fatal(msg, err, appCode, fields)
 }
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"io"
 	"strings"
 	"testing"
 	"time"
@@ -109,6 +110,68 @@ func TestFatalUsesExitFuncWithoutTerminatingProcess(t *testing.T) {
 	}
 }
 
+func TestFatalFlushesSinksBeforeExit(t *testing.T) {
+	for name, attach := range map[string]func(*BatchWriter) Option{
+		"sink":   func(sink *BatchWriter) Option { return WithSink(sink) },
+		"output": func(sink *BatchWriter) Option { return WithOutputs(io.Discard, sink) },
+	} {
+		sender := &recordingSender{}
+		sink, err := NewBatchWriter(sender, WithBatchSize(100, 0), WithBatchAge(time.Hour))
+		if err != nil {
+			t.Fatalf("failed to create sink: %v", err)
+		}
+		defer sink.Close()
+
+		var sentBeforeExit int
+		log := MustNew(
+			attach(sink),
+			WithExitFunc(func(int) {
+				sender.mu.Lock()
+				defer sender.mu.Unlock()
+				for _, batch := range sender.batches {
+					sentBeforeExit += len(batch)
+				}
+			}),
+		)
+		defer log.Close()
+
+		log.Fatal("cannot start", errors.New("boom"), 9001, nil)
+
+		if sentBeforeExit != 1 {
+			t.Fatalf("%s: expected the fatal record to reach the sink before exit, got %d records", name, sentBeforeExit)
+		}
+	}
+}
+
+func TestWithClockProducesByteIdenticalOutput(t *testing.T) {
+	frozen := time.Date(2026, 5, 11, 13, 0, 0, 123456789, time.UTC)
+	render := func() string {
+		var output bytes.Buffer
+		log := MustNew(
+			WithOutput(&output),
+			WithFormat(FormatJSON),
+			WithClock(func() time.Time { return frozen }),
+			WithField("service", "billing"),
+		)
+		log.Info("processed payment", Fields{"attempt": 2})
+		log.Warn("slow payment", 7, nil)
+		log.Slog().Info("payment settled", "attempt", 3)
+		return output.String()
+	}
+
+	expected := `{"timestamp":"2026-05-11T13:00:00.123456789Z","level":"INFO","message":"processed payment","service":"billing","attempt":2}
+{"timestamp":"2026-05-11T13:00:00.123456789Z","level":"WARN","message":"slow payment","service":"billing","app_code":7}
+{"timestamp":"2026-05-11T13:00:00.123456789Z","level":"INFO","message":"payment settled","service":"billing","attempt":3}
+`
+	first := render()
+	if first != expected {
+		t.Fatalf("unexpected golden output:\n%s\nwant:\n%s", first, expected)
+	}
+	if second := render(); second != first {
+		t.Fatalf("output is not deterministic:\n%s\n%s", first, second)
+	}
+}
+
 func decodeSingleRecord(t *testing.T, raw string) map[string]any {
 	t.Helper()
 
@@ -123,3 +186,20 @@ func decodeSingleRecord(t *testing.T, raw string) map[string]any {
 	}
 	return record
 }
+
+func decodeRecords(t *testing.T, raw string) []map[string]any {
+	t.Helper()
+
+	var records []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(raw), "\n") {
+		if line == "" {
+			continue
+		}
+		var record map[string]any
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("failed to unmarshal log record %q: %v", line, err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
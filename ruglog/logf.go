@@ -0,0 +1,166 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+func (l *Logger) wants(ctx context.Context, level Level) bool {
+	log := l.effective()
+	if level == LevelFatal || log.buffer.holds(level) || log.base.Enabled(ctx, slog.Level(level)) {
+		return true
+	}
+	return log.backtraceRing(ctx) != nil && level >= log.state.backtraceLevel
+}
+
+func (l *Logger) logf(level Level, format string, args ...any) {
+	ctx := context.Background()
+	if !l.wants(ctx, level) {
+		return
+	}
+	l.LogContext(ctx, level, fmt.Sprintf(format, args...), firstError(args), 0, nil)
+}
+
+func firstError(args []any) error {
+	for _, arg := range args {
+		if err, ok := arg.(error); ok && err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (l *Logger) logw(level Level, msg string, keysAndValues []any) {
+	ctx := context.Background()
+	if !l.wants(ctx, level) {
+		return
+	}
+	l.LogContext(ctx, level, msg, nil, 0, argsToFields(keysAndValues))
+}
+
+func argsToFields(args []any) Fields {
+	if len(args) == 0 {
+		return nil
+	}
+	var record slog.Record
+	record.Add(args...)
+	fields := make(Fields, record.NumAttrs())
+	record.Attrs(func(attr slog.Attr) bool {
+		addFieldAttr(fields, attr)
+		return true
+	})
+	return fields
+}
+
+func (l *Logger) Tracef(format string, args ...any) {
+	l.logf(LevelTrace, format, args...)
+}
+
+func (l *Logger) Debugf(format string, args ...any) {
+	l.logf(LevelDebug, format, args...)
+}
+
+func (l *Logger) Infof(format string, args ...any) {
+	l.logf(LevelInfo, format, args...)
+}
+
+func (l *Logger) Warnf(format string, args ...any) {
+	l.logf(LevelWarn, format, args...)
+}
+
+func (l *Logger) Errorf(format string, args ...any) {
+	l.logf(LevelError, format, args...)
+}
+
+func (l *Logger) Panicf(format string, args ...any) {
+	l.Panic(fmt.Sprintf(format, args...), firstError(args), 0, nil)
+}
+
+func (l *Logger) Fatalf(format string, args ...any) {
+	l.logf(LevelFatal, format, args...)
+}
+
+func (l *Logger) Tracew(msg string, keysAndValues ...any) {
+	l.logw(LevelTrace, msg, keysAndValues)
+}
+
+func (l *Logger) Debugw(msg string, keysAndValues ...any) {
+	l.logw(LevelDebug, msg, keysAndValues)
+}
+
+func (l *Logger) Infow(msg string, keysAndValues ...any) {
+	l.logw(LevelInfo, msg, keysAndValues)
+}
+
+func (l *Logger) Warnw(msg string, keysAndValues ...any) {
+	l.logw(LevelWarn, msg, keysAndValues)
+}
+
+func (l *Logger) Errorw(msg string, keysAndValues ...any) {
+	l.logw(LevelError, msg, keysAndValues)
+}
+
+func (l *Logger) Panicw(msg string, keysAndValues ...any) {
+	l.Panic(msg, nil, 0, argsToFields(keysAndValues))
+}
+
+func (l *Logger) Fatalw(msg string, keysAndValues ...any) {
+	l.logw(LevelFatal, msg, keysAndValues)
+}
+
+func Tracef(format string, args ...any) {
+	Get().logf(LevelTrace, format, args...)
+}
+
+func Debugf(format string, args ...any) {
+	Get().logf(LevelDebug, format, args...)
+}
+
+func Infof(format string, args ...any) {
+	Get().logf(LevelInfo, format, args...)
+}
+
+func Warnf(format string, args ...any) {
+	Get().logf(LevelWarn, format, args...)
+}
+
+func Errorf(format string, args ...any) {
+	Get().logf(LevelError, format, args...)
+}
+
+func Panicf(format string, args ...any) {
+	Get().Panicf(format, args...)
+}
+
+func Fatalf(format string, args ...any) {
+	Get().logf(LevelFatal, format, args...)
+}
+
+func Tracew(msg string, keysAndValues ...any) {
+	Get().logw(LevelTrace, msg, keysAndValues)
+}
+
+func Debugw(msg string, keysAndValues ...any) {
+	Get().logw(LevelDebug, msg, keysAndValues)
+}
+
+func Infow(msg string, keysAndValues ...any) {
+	Get().logw(LevelInfo, msg, keysAndValues)
+}
+
+func Warnw(msg string, keysAndValues ...any) {
+	Get().logw(LevelWarn, msg, keysAndValues)
+}
+
+func Errorw(msg string, keysAndValues ...any) {
+	Get().logw(LevelError, msg, keysAndValues)
+}
+
+func Panicw(msg string, keysAndValues ...any) {
+	Get().Panicw(msg, keysAndValues...)
+}
+
+func Fatalw(msg string, keysAndValues ...any) {
+	Get().logw(LevelFatal, msg, keysAndValues)
+}
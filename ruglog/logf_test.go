@@ -0,0 +1,83 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+type countingStringer struct {
+	calls *int
+}
+
+func (s countingStringer) String() string {
+	*s.calls++
+	return "expensive"
+}
+
+func TestPrintfVariantsFormatLazily(t *testing.T) {
+	var output bytes.Buffer
+	log := MustNew(WithOutput(&output), WithLevel(LevelInfo))
+
+	calls := 0
+	log.Debugf("state: %s", countingStringer{calls: &calls})
+	if calls != 0 || output.Len() != 0 {
+		t.Fatalf("disabled Debugf must not format, calls=%d output=%s", calls, output.String())
+	}
+
+	log.Infof("loaded %d items from %s", 3, countingStringer{calls: &calls})
+	log.Errorf("sync of %s failed: %v", "orders", errors.New("timeout"))
+
+	records := decodeRecords(t, output.String())
+	if calls != 1 || records[0]["message"] != "loaded 3 items from expensive" {
+		t.Fatalf("unexpected Infof record (calls=%d): %#v", calls, records[0])
+	}
+	if records[1]["level"] != "ERROR" || records[1]["message"] != "sync of orders failed: timeout" || records[1]["error"] != "timeout" {
+		t.Fatalf("unexpected Errorf record: %#v", records[1])
+	}
+}
+
+func TestKeyValueVariantsFollowSlogConventions(t *testing.T) {
+	var output bytes.Buffer
+	log := MustNew(WithOutput(&output))
+
+	log.Warnw("slow query", "table", "orders", "duration_ms", 1250, "app_code", 2001, "dangling")
+	log.Errorw("payment failed", "error", errors.New("declined"))
+
+	records := decodeRecords(t, output.String())
+	warn := records[0]
+	if warn["level"] != "WARN" || warn["table"] != "orders" || warn["duration_ms"] != float64(1250) || warn["app_code"] != float64(2001) || warn["!BADKEY"] != "dangling" {
+		t.Fatalf("unexpected Warnw record: %#v", warn)
+	}
+	if records[1]["error"] != "declined" {
+		t.Fatalf("errors must be rendered as strings: %#v", records[1])
+	}
+}
+
+func TestPanicVariantsLogThenPanic(t *testing.T) {
+	var output bytes.Buffer
+	log := MustNew(WithOutput(&output))
+	cause := errors.New("ledger mismatch")
+
+	recovered := func(fn func()) (value any) {
+		defer func() { value = recover() }()
+		fn()
+		return nil
+	}
+
+	if value := recovered(func() { log.Panicf("balance check for %s: %v", "acc-1", cause) }); value != cause {
+		t.Fatalf("Panicf must panic with the error argument, got %v", value)
+	}
+	value := recovered(func() { log.Panicw("invariant broken", "account", "acc-2") })
+	if err, ok := value.(error); !ok || err.Error() != "invariant broken" {
+		t.Fatalf("Panicw must panic with the message as error, got %v", value)
+	}
+
+	records := decodeRecords(t, output.String())
+	if len(records) != 2 || records[0]["level"] != "PANIC" || records[0]["message"] != "balance check for acc-1: ledger mismatch" || records[0]["error"] != "ledger mismatch" {
+		t.Fatalf("unexpected Panicf record: %#v", records)
+	}
+	if records[1]["level"] != "PANIC" || records[1]["account"] != "acc-2" {
+		t.Fatalf("unexpected Panicw record: %#v", records[1])
+	}
+}
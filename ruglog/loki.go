@@ -0,0 +1,343 @@
+package logger
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	lokiPushPath  = "/loki/api/v1/push"
+	lokiSinkScope = sinkScopeHTTP | sinkScopeLoki
+)
+
+type LokiEncoding string
+
+const (
+	LokiJSON     LokiEncoding = "json"
+	LokiProtobuf LokiEncoding = "protobuf"
+)
+
+func WithLokiLabels(keys ...string) SinkOption {
+	return func(cfg *sinkConfig) error {
+		cfg.use("WithLokiLabels", sinkScopeLoki)
+		labels := make([]string, 0, len(keys))
+		for _, key := range keys {
+			key = strings.TrimSpace(key)
+			if key == "" {
+				continue
+			}
+			if !validLokiLabelName(key) {
+				return fmt.Errorf("invalid loki label name %q", key)
+			}
+			labels = append(labels, key)
+		}
+		cfg.lokiLabels = labels
+		return nil
+	}
+}
+
+func WithLokiEncoding(encoding LokiEncoding) SinkOption {
+	return func(cfg *sinkConfig) error {
+		cfg.use("WithLokiEncoding", sinkScopeLoki)
+		switch encoding {
+		case LokiJSON, LokiProtobuf:
+			cfg.lokiFormat = encoding
+			return nil
+		default:
+			return fmt.Errorf("unsupported loki encoding %q", encoding)
+		}
+	}
+}
+
+func WithLokiTenant(tenant string) SinkOption {
+	return func(cfg *sinkConfig) error {
+		cfg.use("WithLokiTenant", sinkScopeLoki)
+		cfg.lokiTenant = strings.TrimSpace(tenant)
+		return nil
+	}
+}
+
+type LokiSender struct {
+	endpoint string
+	cfg      sinkConfig
+	now      func() time.Time
+}
+
+func NewLokiSender(endpoint string, opts ...SinkOption) (*LokiSender, error) {
+	cfg, err := newSinkConfig("loki", lokiSinkScope, opts)
+	if err != nil {
+		return nil, err
+	}
+	return newLokiSender(endpoint, cfg)
+}
+
+func newLokiSender(endpoint string, cfg sinkConfig) (*LokiSender, error) {
+	pushURL, err := sinkEndpoint(endpoint, lokiPushPath)
+	if err != nil {
+		return nil, err
+	}
+	return &LokiSender{
+		endpoint: pushURL,
+		cfg:      cfg,
+		now:      time.Now,
+	}, nil
+}
+
+func NewLokiWriter(endpoint string, opts ...SinkOption) (*BatchWriter, error) {
+	cfg, err := newSinkConfig("loki", lokiSinkScope|sinkScopeBatch, opts)
+	if err != nil {
+		return nil, err
+	}
+	sender, err := newLokiSender(endpoint, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return newBatchWriter(sender, cfg)
+}
+
+type lokiEntry struct {
+	timestamp time.Time
+	line      string
+}
+
+type lokiStream struct {
+	labels  map[string]string
+	key     string
+	entries []lokiEntry
+}
+
+func (s *LokiSender) SendBatch(ctx context.Context, lines [][]byte) error {
+	streams := s.groupStreams(lines)
+	if len(streams) == 0 {
+		return nil
+	}
+
+	headers := http.Header{}
+	if s.cfg.lokiTenant != "" {
+		headers.Set("X-Scope-OrgID", s.cfg.lokiTenant)
+	}
+
+	switch s.cfg.lokiFormat {
+	case LokiProtobuf:
+		body := snappyEncode(encodeLokiProtobuf(streams))
+		headers.Set("Content-Encoding", "snappy")
+		_, err := postSinkRequest(ctx, s.cfg, s.endpoint, "application/x-protobuf", body, headers)
+		return err
+	default:
+		body, err := encodeLokiJSON(streams)
+		if err != nil {
+			return err
+		}
+		_, err = postSinkRequest(ctx, s.cfg, s.endpoint, "application/json", body, headers)
+		return err
+	}
+}
+
+func (s *LokiSender) groupStreams(lines [][]byte) []*lokiStream {
+	byKey := map[string]*lokiStream{}
+	ordered := make([]*lokiStream, 0, 1)
+	now := s.now()
+
+	for _, line := range lines {
+		record := decodeRecordLine(line)
+		labels := map[string]string{}
+		for _, key := range s.cfg.lokiLabels {
+			if value := recordString(record, key); value != "" {
+				labels[key] = value
+				delete(record, key)
+			}
+		}
+
+		encoded, err := json.Marshal(record)
+		if err != nil {
+			encoded = line
+		}
+
+		key := lokiLabelString(labels)
+		stream, ok := byKey[key]
+		if !ok {
+			stream = &lokiStream{labels: labels, key: key}
+			byKey[key] = stream
+			ordered = append(ordered, stream)
+		}
+		stream.entries = append(stream.entries, lokiEntry{
+			timestamp: recordTime(record, now),
+			line:      string(encoded),
+		})
+	}
+	return ordered
+}
+
+func encodeLokiJSON(streams []*lokiStream) ([]byte, error) {
+	type jsonStream struct {
+		Stream map[string]string `json:"stream"`
+		Values [][2]string       `json:"values"`
+	}
+	payload := struct {
+		Streams []jsonStream `json:"streams"`
+	}{Streams: make([]jsonStream, 0, len(streams))}
+
+	for _, stream := range streams {
+		values := make([][2]string, 0, len(stream.entries))
+		for _, entry := range stream.entries {
+			values = append(values, [2]string{strconv.FormatInt(entry.timestamp.UnixNano(), 10), entry.line})
+		}
+		payload.Streams = append(payload.Streams, jsonStream{Stream: stream.labels, Values: values})
+	}
+	return json.Marshal(payload)
+}
+
+func encodeLokiProtobuf(streams []*lokiStream) []byte {
+	var request []byte
+	for _, stream := range streams {
+		var encoded []byte
+		encoded = appendProtoBytes(encoded, 1, []byte(stream.key))
+		for _, entry := range stream.entries {
+			var timestamp []byte
+			timestamp = appendProtoVarintField(timestamp, 1, uint64(entry.timestamp.Unix()))
+			timestamp = appendProtoVarintField(timestamp, 2, uint64(entry.timestamp.Nanosecond()))
+
+			var protoEntry []byte
+			protoEntry = appendProtoBytes(protoEntry, 1, timestamp)
+			protoEntry = appendProtoBytes(protoEntry, 2, []byte(entry.line))
+			encoded = appendProtoBytes(encoded, 2, protoEntry)
+		}
+		request = appendProtoBytes(request, 1, encoded)
+	}
+	return request
+}
+
+func appendProtoVarintField(buf []byte, field int, value uint64) []byte {
+	if value == 0 {
+		return buf
+	}
+	buf = appendUvarint(buf, uint64(field)<<3)
+	return appendUvarint(buf, value)
+}
+
+func appendProtoBytes(buf []byte, field int, value []byte) []byte {
+	buf = appendUvarint(buf, uint64(field)<<3|2)
+	buf = appendUvarint(buf, uint64(len(value)))
+	return append(buf, value...)
+}
+
+func appendUvarint(buf []byte, value uint64) []byte {
+	for value >= 0x80 {
+		buf = append(buf, byte(value)|0x80)
+		value >>= 7
+	}
+	return append(buf, byte(value))
+}
+
+func lokiLabelString(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var builder strings.Builder
+	builder.WriteByte('{')
+	for i, key := range keys {
+		if i > 0 {
+			builder.WriteString(", ")
+		}
+		builder.WriteString(key)
+		builder.WriteByte('=')
+		builder.WriteString(strconv.Quote(labels[key]))
+	}
+	builder.WriteByte('}')
+	return builder.String()
+}
+
+func validLokiLabelName(name string) bool {
+	for i, r := range name {
+		switch {
+		case r == '_', r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+		case i > 0 && r >= '0' && r <= '9':
+		default:
+			return false
+		}
+	}
+	return name != ""
+}
+
+func snappyEncode(src []byte) []byte {
+	dst := appendUvarint(make([]byte, 0, len(src)+len(src)/6+16), uint64(len(src)))
+	if len(src) < 8 {
+		return appendSnappyLiteral(dst, src)
+	}
+
+	const tableBits = 14
+	var table [1 << tableBits]int32
+	hash := func(u uint32) uint32 {
+		return (u * 0x1e35a7bd) >> (32 - tableBits)
+	}
+	load32 := func(i int) uint32 {
+		return uint32(src[i]) | uint32(src[i+1])<<8 | uint32(src[i+2])<<16 | uint32(src[i+3])<<24
+	}
+
+	literalStart := 0
+	for i := 0; i+4 <= len(src); {
+		current := load32(i)
+		slot := hash(current)
+		candidate := int(table[slot]) - 1
+		table[slot] = int32(i + 1)
+
+		if candidate < 0 || i-candidate > 0xffff || load32(candidate) != current {
+			i++
+			continue
+		}
+
+		length := 4
+		for i+length < len(src) && src[candidate+length] == src[i+length] {
+			length++
+		}
+		dst = appendSnappyLiteral(dst, src[literalStart:i])
+		dst = appendSnappyCopy(dst, i-candidate, length)
+		i += length
+		literalStart = i
+	}
+	return appendSnappyLiteral(dst, src[literalStart:])
+}
+
+func appendSnappyLiteral(dst []byte, literal []byte) []byte {
+	if len(literal) == 0 {
+		return dst
+	}
+	n := len(literal) - 1
+	switch {
+	case n < 60:
+		dst = append(dst, byte(n)<<2)
+	case n < 1<<8:
+		dst = append(dst, 60<<2, byte(n))
+	case n < 1<<16:
+		dst = append(dst, 61<<2, byte(n), byte(n>>8))
+	case n < 1<<24:
+		dst = append(dst, 62<<2, byte(n), byte(n>>8), byte(n>>16))
+	default:
+		dst = append(dst, 63<<2, byte(n), byte(n>>8), byte(n>>16), byte(n>>24))
+	}
+	return append(dst, literal...)
+}
+
+func appendSnappyCopy(dst []byte, offset int, length int) []byte {
+	for length >= 68 {
+		dst = append(dst, 63<<2|2, byte(offset), byte(offset>>8))
+		length -= 64
+	}
+	if length > 64 {
+		dst = append(dst, 59<<2|2, byte(offset), byte(offset>>8))
+		length -= 60
+	}
+	if length >= 12 || offset >= 2048 {
+		return append(dst, byte(length-1)<<2|2, byte(offset), byte(offset>>8))
+	}
+	return append(dst, byte(offset>>8)<<5|byte(length-4)<<2|1, byte(offset))
+}
@@ -0,0 +1,210 @@
+package logger
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLokiWriterPushesJSONStreamsWithRetry(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		attempts int
+		payloads [][]byte
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		mu.Lock()
+		defer mu.Unlock()
+		attempts++
+		if r.URL.Path != "/loki/api/v1/push" {
+			t.Errorf("unexpected push path: %s", r.URL.Path)
+		}
+		if r.Header.Get("X-Scope-OrgID") != "team-a" {
+			t.Errorf("missing tenant header: %q", r.Header.Get("X-Scope-OrgID"))
+		}
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		payloads = append(payloads, body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	sink, err := NewLokiWriter(server.URL,
+		WithLokiTenant("team-a"),
+		WithBatchSize(10, 0),
+		WithBatchAge(time.Hour),
+		WithRetry(3, time.Millisecond, time.Millisecond),
+	)
+	if err != nil {
+		t.Fatalf("failed to create loki writer: %v", err)
+	}
+
+	log := MustNew(WithOutput(sink), WithField("service", "billing"))
+	log.Info("charged", Fields{"amount": 10})
+	log.Warn("slow charge", 7, nil)
+	if err := sink.Close(); err != nil {
+		t.Fatalf("close failed: %v", err)
+	}
+
+	if len(payloads) != 1 {
+		t.Fatalf("expected one accepted push, got %d after %d attempts", len(payloads), attempts)
+	}
+	if stats := sink.Stats(); stats.Sent != 2 || stats.Retries != 1 || stats.Dropped != 0 {
+		t.Fatalf("unexpected sink stats: %+v", stats)
+	}
+
+	var push struct {
+		Streams []struct {
+			Stream map[string]string `json:"stream"`
+			Values [][2]string       `json:"values"`
+		} `json:"streams"`
+	}
+	if err := json.Unmarshal(payloads[0], &push); err != nil {
+		t.Fatalf("invalid push payload %s: %v", payloads[0], err)
+	}
+	if len(push.Streams) != 2 {
+		t.Fatalf("expected a stream per level, got %+v", push.Streams)
+	}
+
+	info := push.Streams[0]
+	if info.Stream["service"] != "billing" || info.Stream["level"] != "INFO" {
+		t.Fatalf("unexpected stream labels: %#v", info.Stream)
+	}
+	var line map[string]any
+	if err := json.Unmarshal([]byte(info.Values[0][1]), &line); err != nil {
+		t.Fatalf("invalid log line %q: %v", info.Values[0][1], err)
+	}
+	if line["message"] != "charged" || line["amount"] != float64(10) {
+		t.Fatalf("unexpected log line: %#v", line)
+	}
+	if _, ok := line["service"]; ok {
+		t.Fatalf("label field should be removed from line: %#v", line)
+	}
+}
+
+func TestLokiSenderEncodesSnappyProtobuf(t *testing.T) {
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/x-protobuf" || r.Header.Get("Content-Encoding") != "snappy" {
+			t.Errorf("unexpected content headers: %v", r.Header)
+		}
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	sender, err := NewLokiSender(server.URL, WithLokiEncoding(LokiProtobuf), WithLokiLabels("level"))
+	if err != nil {
+		t.Fatalf("failed to create loki sender: %v", err)
+	}
+
+	line := []byte(`{"timestamp":"2026-05-11T13:00:00.5Z","level":"ERROR","message":"repeat repeat repeat repeat repeat"}`)
+	if err := sender.SendBatch(t.Context(), [][]byte{line}); err != nil {
+		t.Fatalf("send failed: %v", err)
+	}
+
+	raw, err := snappyDecodeForTest(body)
+	if err != nil {
+		t.Fatalf("invalid snappy payload: %v", err)
+	}
+	stream := protoFieldsForTest(t, raw)[1][0]
+	fields := protoFieldsForTest(t, stream)
+	if string(fields[1][0]) != `{level="ERROR"}` {
+		t.Fatalf("unexpected label set: %q", fields[1][0])
+	}
+	entry := protoFieldsForTest(t, fields[2][0])
+	var decoded map[string]any
+	if err := json.Unmarshal(entry[2][0], &decoded); err != nil {
+		t.Fatalf("invalid entry line %q: %v", entry[2][0], err)
+	}
+	if decoded["message"] != "repeat repeat repeat repeat repeat" {
+		t.Fatalf("unexpected entry line: %#v", decoded)
+	}
+}
+
+func protoFieldsForTest(t *testing.T, raw []byte) map[int][][]byte {
+	t.Helper()
+
+	fields := map[int][][]byte{}
+	for len(raw) > 0 {
+		key, n := readUvarintForTest(raw)
+		raw = raw[n:]
+		if key&7 != 2 {
+			_, n = readUvarintForTest(raw)
+			raw = raw[n:]
+			continue
+		}
+		length, n := readUvarintForTest(raw)
+		raw = raw[n:]
+		if int(length) > len(raw) {
+			t.Fatalf("truncated protobuf field %d", key>>3)
+		}
+		fields[int(key>>3)] = append(fields[int(key>>3)], raw[:length])
+		raw = raw[length:]
+	}
+	return fields
+}
+
+func readUvarintForTest(raw []byte) (uint64, int) {
+	var value uint64
+	for i, b := range raw {
+		value |= uint64(b&0x7f) << (7 * i)
+		if b < 0x80 {
+			return value, i + 1
+		}
+	}
+	return value, len(raw)
+}
+
+func snappyDecodeForTest(src []byte) ([]byte, error) {
+	length, n := readUvarintForTest(src)
+	src = src[n:]
+	dst := make([]byte, 0, length)
+	for len(src) > 0 {
+		tag := src[0]
+		switch tag & 3 {
+		case 0:
+			size := int(tag >> 2)
+			src = src[1:]
+			if size >= 60 {
+				extra := size - 59
+				size = 0
+				for i := 0; i < extra; i++ {
+					size |= int(src[i]) << (8 * i)
+				}
+				src = src[extra:]
+			}
+			size++
+			dst = append(dst, src[:size]...)
+			src = src[size:]
+		case 1:
+			size := int(tag>>2&7) + 4
+			offset := int(tag>>5)<<8 | int(src[1])
+			src = src[2:]
+			for i := 0; i < size; i++ {
+				dst = append(dst, dst[len(dst)-offset])
+			}
+		case 2:
+			size := int(tag>>2) + 1
+			offset := int(src[1]) | int(src[2])<<8
+			src = src[3:]
+			for i := 0; i < size; i++ {
+				dst = append(dst, dst[len(dst)-offset])
+			}
+		default:
+			return nil, io.ErrUnexpectedEOF
+		}
+	}
+	if uint64(len(dst)) != length {
+		return nil, io.ErrUnexpectedEOF
+	}
+	return dst, nil
+}
@@ -0,0 +1,60 @@
+package logger
+
+import (
+	"os"
+	"runtime"
+	"runtime/debug"
+)
+
+var readBuildInfo = debug.ReadBuildInfo
+
+var kubernetesEnv = []struct {
+	env string
+	key string
+}{
+	{env: "POD_NAME", key: "pod_name"},
+	{env: "NAMESPACE", key: "namespace"},
+	{env: "NODE_NAME", key: "node_name"},
+}
+
+func WithProcessMetadata() Option {
+	return func(cfg *config) error {
+		metadata := processMetadata()
+		for key := range cfg.defaults {
+			delete(metadata, key)
+		}
+		cfg.defaults = MergeFields(cfg.defaults, metadata)
+		return nil
+	}
+}
+
+func processMetadata() Fields {
+	metadata := Fields{
+		"pid":        os.Getpid(),
+		"go_version": runtime.Version(),
+	}
+	if hostname, err := os.Hostname(); err == nil && hostname != "" {
+		metadata["hostname"] = hostname
+	}
+
+	if info, ok := readBuildInfo(); ok {
+		if info.GoVersion != "" {
+			metadata["go_version"] = info.GoVersion
+		}
+		if version := info.Main.Version; version != "" && version != "(devel)" {
+			metadata["module_version"] = version
+		}
+		for _, setting := range info.Settings {
+			if setting.Key == "vcs.revision" && setting.Value != "" {
+				metadata["vcs_revision"] = setting.Value
+			}
+		}
+	}
+
+	for _, item := range kubernetesEnv {
+		if value := os.Getenv(item.env); value != "" {
+			metadata[item.key] = value
+		}
+	}
+	return metadata
+}
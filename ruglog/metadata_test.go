@@ -0,0 +1,47 @@
+package logger
+
+import (
+	"bytes"
+	"os"
+	"runtime/debug"
+	"testing"
+)
+
+func TestProcessMetadataAddsBuildAndKubernetesFields(t *testing.T) {
+	original := readBuildInfo
+	t.Cleanup(func() { readBuildInfo = original })
+	readBuildInfo = func() (*debug.BuildInfo, bool) {
+		return &debug.BuildInfo{
+			GoVersion: "go1.24.2",
+			Main:      debug.Module{Path: "example.com/orders", Version: "v1.4.0"},
+			Settings:  []debug.BuildSetting{{Key: "vcs.revision", Value: "3f2c9e1"}},
+		}, true
+	}
+	t.Setenv("POD_NAME", "orders-7d9f")
+	t.Setenv("NAMESPACE", "payments")
+	t.Setenv("NODE_NAME", "")
+
+	var output bytes.Buffer
+	log := MustNew(WithOutput(&output), WithField("namespace", "override"), WithProcessMetadata())
+	log.Info("started", nil)
+
+	record := decodeRecords(t, output.String())[0]
+	hostname, _ := os.Hostname()
+	expected := map[string]any{
+		"pid":            float64(os.Getpid()),
+		"hostname":       hostname,
+		"go_version":     "go1.24.2",
+		"module_version": "v1.4.0",
+		"vcs_revision":   "3f2c9e1",
+		"pod_name":       "orders-7d9f",
+		"namespace":      "override",
+	}
+	for key, value := range expected {
+		if record[key] != value {
+			t.Fatalf("%q = %#v, want %#v in %#v", key, record[key], value, record)
+		}
+	}
+	if _, ok := record["node_name"]; ok {
+		t.Fatalf("empty env values must be skipped: %#v", record)
+	}
+}
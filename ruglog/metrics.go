@@ -0,0 +1,200 @@
+package logger
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+const LoggerNameKey = "logger"
+
+func WithName(name string) Option {
+	return func(cfg *config) error {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return nil
+		}
+		cfg.defaults = MergeFields(cfg.defaults, Fields{LoggerNameKey: name})
+		return nil
+	}
+}
+
+func (l *Logger) Named(name string) *Logger {
+	log := l.effective()
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return log
+	}
+	if log.name != "" {
+		name = log.name + "." + name
+	}
+	child := log.clone(log.base.With(LoggerNameKey, name))
+	child.name = name
+	return child
+}
+
+type recordKey struct {
+	logger  string
+	level   Level
+	appCode int64
+}
+
+type metrics struct {
+	records sync.Map
+	outputs []*outputWriter
+}
+
+func (m *metrics) countRecord(logger string, level Level, appCode int64) {
+	key := recordKey{logger: logger, level: level, appCode: appCode}
+	counter, ok := m.records.Load(key)
+	if !ok {
+		counter, _ = m.records.LoadOrStore(key, &atomic.Uint64{})
+	}
+	counter.(*atomic.Uint64).Add(1)
+}
+
+type outputMetrics struct {
+	name          string
+	writtenBytes  atomic.Uint64
+	fallbackBytes atomic.Uint64
+	droppedBytes  atomic.Uint64
+	failures      atomic.Uint64
+}
+
+func recordAppCode(record slog.Record) int64 {
+	var appCode int64
+	record.Attrs(func(attr slog.Attr) bool {
+		if attr.Key != "app_code" {
+			return true
+		}
+		switch value := attr.Value.Resolve(); value.Kind() {
+		case slog.KindInt64:
+			appCode = value.Int64()
+		case slog.KindUint64:
+			appCode = int64(value.Uint64())
+		}
+		return false
+	})
+	return appCode
+}
+
+func outputNames(outputs []io.Writer) []string {
+	names := make([]string, len(outputs))
+	seen := map[string]int{}
+	for i, output := range outputs {
+		name := describeOutput(output)
+		seen[name]++
+		if seen[name] > 1 {
+			name = fmt.Sprintf("%s#%d", name, seen[name])
+		}
+		names[i] = name
+	}
+	return names
+}
+
+func describeOutput(output io.Writer) string {
+	switch typed := output.(type) {
+	case *os.File:
+		return typed.Name()
+	case *BatchWriter:
+		name := reflect.TypeOf(typed.sender).String()
+		name = name[strings.LastIndex(name, ".")+1:]
+		return "sink:" + strings.TrimSuffix(strings.ToLower(name), "sender")
+	case *GELFWriter:
+		return "sink:gelf"
+	default:
+		return strings.TrimPrefix(reflect.TypeOf(output).String(), "*")
+	}
+}
+
+func MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		Get().MetricsHandler().ServeHTTP(w, r)
+	})
+}
+
+func (l *Logger) MetricsHandler() http.Handler {
+	state := l.effective().state
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_, _ = w.Write(state.renderMetrics())
+	})
+}
+
+func (s *sharedState) renderMetrics() []byte {
+	var buffer bytes.Buffer
+
+	type recordSample struct {
+		key   recordKey
+		count uint64
+	}
+	var samples []recordSample
+	s.metrics.records.Range(func(key, value any) bool {
+		samples = append(samples, recordSample{key: key.(recordKey), count: value.(*atomic.Uint64).Load()})
+		return true
+	})
+	sort.Slice(samples, func(i, j int) bool {
+		a, b := samples[i].key, samples[j].key
+		if a.logger != b.logger {
+			return a.logger < b.logger
+		}
+		if a.level != b.level {
+			return a.level < b.level
+		}
+		return a.appCode < b.appCode
+	})
+
+	writeMetricHeader(&buffer, "ruglog_records_total", "Log records written by logger name, level and app code.")
+	for _, sample := range samples {
+		fmt.Fprintf(&buffer, "ruglog_records_total{logger=%s,level=%s,app_code=%s} %d\n",
+			quoteLabel(sample.key.logger), quoteLabel(sample.key.level.String()),
+			quoteLabel(strconv.FormatInt(sample.key.appCode, 10)), sample.count)
+	}
+
+	outputCounters := []struct {
+		name  string
+		help  string
+		value func(*outputWriter) uint64
+	}{
+		{"ruglog_output_written_bytes_total", "Bytes written to the primary output.", func(w *outputWriter) uint64 { return w.metrics.writtenBytes.Load() }},
+		{"ruglog_output_fallback_bytes_total", "Bytes written to the fallback output after a primary failure.", func(w *outputWriter) uint64 { return w.metrics.fallbackBytes.Load() }},
+		{"ruglog_output_dropped_bytes_total", "Bytes that reached neither the primary nor the fallback output.", func(w *outputWriter) uint64 { return w.metrics.droppedBytes.Load() }},
+		{"ruglog_output_write_failures_total", "Failed writes to the primary output.", func(w *outputWriter) uint64 { return w.metrics.failures.Load() }},
+	}
+	for _, counter := range outputCounters {
+		writeMetricHeader(&buffer, counter.name, counter.help)
+		for _, output := range s.metrics.outputs {
+			fmt.Fprintf(&buffer, "%s{output=%s} %d\n", counter.name, quoteLabel(output.metrics.name), counter.value(output))
+		}
+	}
+
+	writeMetricHeader(&buffer, "ruglog_sink_dropped_records_total", "Records dropped by batched remote sinks.")
+	for _, output := range s.metrics.outputs {
+		if sink, ok := output.primary.(interface{ Stats() SinkStats }); ok {
+			fmt.Fprintf(&buffer, "ruglog_sink_dropped_records_total{output=%s} %d\n", quoteLabel(output.metrics.name), sink.Stats().Dropped)
+		}
+	}
+
+	writeMetricHeader(&buffer, "ruglog_write_errors_total", "Write errors reported by the logger.")
+	fmt.Fprintf(&buffer, "ruglog_write_errors_total %d\n", s.writeErrors.Load())
+	return buffer.Bytes()
+}
+
+func writeMetricHeader(buffer *bytes.Buffer, name string, help string) {
+	fmt.Fprintf(buffer, "# HELP %s %s\n# TYPE %s counter\n", name, help, name)
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func quoteLabel(value string) string {
+	return `"` + labelEscaper.Replace(value) + `"`
+}
@@ -0,0 +1,67 @@
+package logger
+
+import (
+	"bytes"
+	"io"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestMetricsHandlerExposesRecordAndOutputCounters(t *testing.T) {
+	var healthy bytes.Buffer
+	broken := &toggleWriter{err: syscall.ENOSPC}
+	var fallback bytes.Buffer
+
+	log := MustNew(
+		WithName("billing"),
+		WithOutputs(&healthy, broken),
+		WithFallbackOutput(&fallback, time.Hour),
+	)
+
+	log.Info("started", nil)
+	worker := log.Named("worker")
+	worker.Warn("slow", 7, nil)
+	worker.Warn("slow", 7, nil)
+	worker.Error("failed", nil, 500, nil)
+	log.Debug("hidden", nil)
+
+	recorder := httptest.NewRecorder()
+	log.MetricsHandler().ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	body := recorder.Body.String()
+
+	if !strings.HasPrefix(recorder.Header().Get("Content-Type"), "text/plain; version=0.0.4") {
+		t.Fatalf("unexpected content type: %q", recorder.Header().Get("Content-Type"))
+	}
+	written := uint64(healthy.Len())
+
+	for _, expected := range []string{
+		"# TYPE ruglog_records_total counter",
+		`ruglog_records_total{logger="billing",level="INFO",app_code="0"} 1`,
+		`ruglog_records_total{logger="billing.worker",level="WARN",app_code="7"} 2`,
+		`ruglog_records_total{logger="billing.worker",level="ERROR",app_code="500"} 1`,
+		`ruglog_output_written_bytes_total{output="bytes.Buffer"} ` + strconv.FormatUint(written, 10),
+		`ruglog_output_written_bytes_total{output="logger.toggleWriter"} 0`,
+		`ruglog_output_fallback_bytes_total{output="logger.toggleWriter"} ` + strconv.FormatUint(written, 10),
+		`ruglog_output_write_failures_total{output="logger.toggleWriter"} 1`,
+		`ruglog_output_dropped_bytes_total{output="bytes.Buffer"} 0`,
+		"ruglog_write_errors_total 1",
+	} {
+		if !strings.Contains(body, expected+"\n") {
+			t.Fatalf("missing %q in metrics:\n%s", expected, body)
+		}
+	}
+	if strings.Contains(body, `level="DEBUG"`) {
+		t.Fatalf("disabled records must not be counted:\n%s", body)
+	}
+}
+
+func TestOutputNamesAreUniqueAndDescriptive(t *testing.T) {
+	names := outputNames([]io.Writer{&bytes.Buffer{}, &bytes.Buffer{}, &BatchWriter{sender: &LokiSender{}}})
+	if strings.Join(names, ",") != "bytes.Buffer,bytes.Buffer#2,sink:loki" {
+		t.Fatalf("unexpected output names: %v", names)
+	}
+}
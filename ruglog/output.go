@@ -0,0 +1,133 @@
+package logger
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"time"
+)
+
+const defaultFallbackRetryInterval = 5 * time.Second
+
+func WithFallbackOutput(fallback io.Writer, retryInterval time.Duration) Option {
+	return func(cfg *config) error {
+		if fallback == nil {
+			return errors.New("logger fallback output cannot be nil")
+		}
+		if retryInterval < 0 {
+			return errors.New("logger fallback retry interval cannot be negative")
+		}
+		if retryInterval == 0 {
+			retryInterval = defaultFallbackRetryInterval
+		}
+		cfg.fallback = fallback
+		cfg.fallbackRetry = retryInterval
+		return nil
+	}
+}
+
+func WithOnWriteError(fn func(error)) Option {
+	return func(cfg *config) error {
+		cfg.onWriteError = fn
+		return nil
+	}
+}
+
+func (l *Logger) WriteErrors() uint64 {
+	return l.effective().state.writeErrors.Load()
+}
+
+type reportedWriteError struct {
+	error
+}
+
+func (e reportedWriteError) Unwrap() error {
+	return e.error
+}
+
+func (s *sharedState) reportWriteError(err error) {
+	var reported reportedWriteError
+	if err == nil || errors.As(err, &reported) {
+		return
+	}
+	s.writeErrors.Add(1)
+	if s.onWriteError != nil {
+		s.onWriteError(err)
+	}
+}
+
+type outputWriter struct {
+	primary       io.Writer
+	fallback      io.Writer
+	retryInterval time.Duration
+	state         *sharedState
+	metrics       *outputMetrics
+
+	mu      sync.Mutex
+	failing bool
+	retryAt time.Time
+}
+
+func (w *outputWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.failing && w.fallback != nil && time.Now().Before(w.retryAt) {
+		if err := w.writeFallback(p); err != nil {
+			return 0, w.report(err)
+		}
+		return len(p), nil
+	}
+
+	n, err := w.primary.Write(p)
+	if err == nil && n < len(p) {
+		err = io.ErrShortWrite
+	}
+	if err == nil {
+		w.failing = false
+		w.metrics.writtenBytes.Add(uint64(n))
+		return n, nil
+	}
+
+	w.failing = true
+	w.retryAt = time.Now().Add(w.retryInterval)
+	w.metrics.failures.Add(1)
+	if w.fallback == nil {
+		w.metrics.droppedBytes.Add(uint64(len(p)))
+		return n, w.report(err)
+	}
+	if fallbackErr := w.writeFallback(p); fallbackErr != nil {
+		return 0, w.report(errors.Join(err, fallbackErr))
+	}
+	w.state.reportWriteError(err)
+	return len(p), nil
+}
+
+func (w *outputWriter) report(err error) error {
+	w.state.reportWriteError(err)
+	return reportedWriteError{err}
+}
+
+func (w *outputWriter) writeFallback(p []byte) error {
+	if _, err := w.fallback.Write(p); err != nil {
+		w.metrics.droppedBytes.Add(uint64(len(p)))
+		return err
+	}
+	w.metrics.fallbackBytes.Add(uint64(len(p)))
+	return nil
+}
+
+type fanoutWriter []io.Writer
+
+func (w fanoutWriter) Write(p []byte) (int, error) {
+	var joined error
+	for _, writer := range w {
+		if _, err := writer.Write(p); err != nil {
+			joined = errors.Join(joined, err)
+		}
+	}
+	if joined != nil {
+		return 0, joined
+	}
+	return len(p), nil
+}
@@ -0,0 +1,103 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+type toggleWriter struct {
+	buffer bytes.Buffer
+	err    error
+}
+
+func (w *toggleWriter) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	return w.buffer.Write(p)
+}
+
+func TestFallbackOutputTakesOverAndRecovers(t *testing.T) {
+	primary := &toggleWriter{err: syscall.ENOSPC}
+	var fallback bytes.Buffer
+	var reported []error
+
+	log := MustNew(
+		WithOutput(primary),
+		WithFallbackOutput(&fallback, time.Nanosecond),
+		WithOnWriteError(func(err error) {
+			reported = append(reported, err)
+		}),
+	)
+
+	log.Info("disk is full", nil)
+	if !strings.Contains(fallback.String(), `"message":"disk is full"`) {
+		t.Fatalf("expected fallback to receive the record, got %q", fallback.String())
+	}
+	if log.WriteErrors() != 1 || len(reported) != 1 || !errors.Is(reported[0], syscall.ENOSPC) {
+		t.Fatalf("expected one reported ENOSPC, got %d errors: %v", log.WriteErrors(), reported)
+	}
+
+	primary.err = nil
+	time.Sleep(time.Millisecond)
+	log.Info("disk recovered", nil)
+	if !strings.Contains(primary.buffer.String(), `"message":"disk recovered"`) {
+		t.Fatalf("expected primary output to be used again, got %q", primary.buffer.String())
+	}
+	if strings.Contains(fallback.String(), "disk recovered") || log.WriteErrors() != 1 {
+		t.Fatalf("recovered write should not hit the fallback: %q", fallback.String())
+	}
+}
+
+func TestWriteErrorsAreReportedWithoutFallback(t *testing.T) {
+	healthy := &toggleWriter{}
+	broken := &toggleWriter{err: syscall.EPIPE}
+	var reported []error
+
+	log := MustNew(
+		WithOutputs(healthy, broken),
+		WithOnWriteError(func(err error) {
+			reported = append(reported, err)
+		}),
+	)
+
+	log.Warn("pipe closed", 0, nil)
+
+	if !strings.Contains(healthy.buffer.String(), `"message":"pipe closed"`) {
+		t.Fatalf("a failing output must not block the others, got %q", healthy.buffer.String())
+	}
+	if log.WriteErrors() != 1 || len(reported) != 1 || !errors.Is(reported[0], syscall.EPIPE) {
+		t.Fatalf("expected one reported EPIPE, got %d errors: %v", log.WriteErrors(), reported)
+	}
+}
+
+func TestFailedFallbackIsReportedOncePerRecord(t *testing.T) {
+	primary := &toggleWriter{err: syscall.ENOSPC}
+	fallback := &toggleWriter{err: syscall.EIO}
+	var reported []error
+
+	log := MustNew(
+		WithOutput(primary),
+		WithFallbackOutput(fallback, time.Hour),
+		WithOnWriteError(func(err error) {
+			reported = append(reported, err)
+		}),
+	)
+
+	log.Info("first", nil)
+	if log.WriteErrors() != 1 || len(reported) != 1 {
+		t.Fatalf("expected one report for the first record, got %d errors: %v", log.WriteErrors(), reported)
+	}
+	if !errors.Is(reported[0], syscall.ENOSPC) || !errors.Is(reported[0], syscall.EIO) {
+		t.Fatalf("report must carry both primary and fallback errors: %v", reported[0])
+	}
+
+	log.Info("second", nil)
+	if log.WriteErrors() != 2 || len(reported) != 2 {
+		t.Fatalf("expected one report per record during the retry window, got %d errors: %v", log.WriteErrors(), reported)
+	}
+}
@@ -0,0 +1,126 @@
+package logger
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+const (
+	PseudonymVersionKey = "pseudonym_key_version"
+
+	minPseudonymSecretLength = 16
+	pseudonymBytes           = 16
+)
+
+type Pseudonymizer struct {
+	mu      sync.RWMutex
+	version string
+	secret  []byte
+}
+
+func NewPseudonymizer(version string, secret []byte) (*Pseudonymizer, error) {
+	p := &Pseudonymizer{}
+	if err := p.Rotate(version, secret); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (p *Pseudonymizer) Rotate(version string, secret []byte) error {
+	version = strings.TrimSpace(version)
+	if version == "" {
+		return errors.New("pseudonymizer key version cannot be empty")
+	}
+	if len(secret) < minPseudonymSecretLength {
+		return fmt.Errorf("pseudonymizer secret must be at least %d bytes", minPseudonymSecretLength)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.version = version
+	p.secret = append([]byte(nil), secret...)
+	return nil
+}
+
+func (p *Pseudonymizer) Version() string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.version
+}
+
+func (p *Pseudonymizer) Pseudonymize(value string) (string, string) {
+	p.mu.RLock()
+	mac := hmac.New(sha256.New, p.secret)
+	version := p.version
+	p.mu.RUnlock()
+
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil)[:pseudonymBytes]), version
+}
+
+func WithPseudonymization(p *Pseudonymizer, keys ...string) Option {
+	return func(cfg *config) error {
+		if p == nil {
+			return errors.New("logger pseudonymizer cannot be nil")
+		}
+		if len(keys) == 0 {
+			return errors.New("at least one pseudonymized key is required")
+		}
+		cfg.pseudonymizer = p
+		cfg.pseudonymizedKeys = mergeKeySets(cfg.pseudonymizedKeys, normalizeKeySet(keys...))
+		return nil
+	}
+}
+
+func (p *pipeline) isPseudonymized(key string) bool {
+	if p.pseudonymizer == nil {
+		return false
+	}
+	_, ok := p.pseudonymizedKeys[strings.ToLower(key)]
+	return ok
+}
+
+func (p *pipeline) pseudonymize(value string, state *rewriteState) string {
+	pseudonym, version := p.pseudonymizer.Pseudonymize(value)
+	state.pseudonymVersion = version
+	return pseudonym
+}
+
+func pseudonymSource(value slog.Value) (string, bool) {
+	switch value.Kind() {
+	case slog.KindGroup:
+		return "", false
+	case slog.KindAny:
+		return anyPseudonymSource(value.Any())
+	default:
+		return value.String(), true
+	}
+}
+
+func anyPseudonymSource(value any) (string, bool) {
+	if valuer, ok := value.(slog.LogValuer); ok {
+		return pseudonymSource(slog.AnyValue(valuer).Resolve())
+	}
+	value = normalizeFieldValue(value)
+	switch typed := value.(type) {
+	case nil:
+		return "", false
+	case string:
+		return typed, true
+	case fmt.Stringer:
+		return typed.String(), true
+	}
+	switch reflect.ValueOf(value).Kind() {
+	case reflect.Map, reflect.Slice, reflect.Array, reflect.Struct:
+		return "", false
+	default:
+		return fmt.Sprint(value), true
+	}
+}
@@ -0,0 +1,62 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestPseudonymizationIsStableAndRotates(t *testing.T) {
+	pseudonymizer, err := NewPseudonymizer("v1", []byte("0123456789abcdef-first"))
+	if err != nil {
+		t.Fatalf("failed to create pseudonymizer: %v", err)
+	}
+
+	var output bytes.Buffer
+	log := MustNew(WithOutput(&output), WithPseudonymization(pseudonymizer, "user_id", "Email"))
+
+	log.Info("login", Fields{"user_id": 42, "client": Fields{"email": "alice@example.com"}})
+	log.WithField("user_id", 42).Info("logout", nil)
+	if err := pseudonymizer.Rotate("v2", []byte("0123456789abcdef-second")); err != nil {
+		t.Fatalf("rotate failed: %v", err)
+	}
+	log.Info("login", Fields{"user_id": 42})
+
+	lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 records, got %q", output.String())
+	}
+	records := make([]map[string]any, len(lines))
+	for i, line := range lines {
+		if err := json.Unmarshal([]byte(line), &records[i]); err != nil {
+			t.Fatalf("invalid record %q: %v", line, err)
+		}
+	}
+
+	if strings.Contains(output.String(), "alice@example.com") || strings.Contains(lines[0], `"user_id":42`) {
+		t.Fatalf("raw identifiers leaked: %s", output.String())
+	}
+	first, _ := pseudonymizer.Pseudonymize("42")
+	if records[0]["user_id"] != records[1]["user_id"] || records[0]["user_id"] == first {
+		t.Fatalf("same key version should give the same pseudonym: %v / %v", records[0]["user_id"], records[1]["user_id"])
+	}
+	if records[2]["user_id"] != first || records[2][PseudonymVersionKey] != "v2" {
+		t.Fatalf("rotated key should be used and reported: %v", records[2])
+	}
+	if records[0][PseudonymVersionKey] != "v1" || records[1][PseudonymVersionKey] != "v1" {
+		t.Fatalf("expected key version v1 on earlier records: %v %v", records[0], records[1])
+	}
+	if email := records[0]["client"].(map[string]any)["email"].(string); len(email) != 32 {
+		t.Fatalf("nested key should be pseudonymized, got %q", email)
+	}
+}
+
+func TestPseudonymizerRejectsWeakKeys(t *testing.T) {
+	if _, err := NewPseudonymizer("", []byte("0123456789abcdef")); err == nil {
+		t.Fatal("expected empty version to be rejected")
+	}
+	if _, err := NewPseudonymizer("v1", []byte("short")); err == nil {
+		t.Fatal("expected short secret to be rejected")
+	}
+}
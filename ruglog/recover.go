@@ -0,0 +1,89 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+)
+
+const (
+	PanicKey = "panic"
+	StackKey = "stack"
+)
+
+type RecoverOption func(*recoverConfig)
+
+type recoverConfig struct {
+	message string
+	fields  Fields
+	repanic bool
+}
+
+func WithRepanic() RecoverOption {
+	return func(cfg *recoverConfig) {
+		cfg.repanic = true
+	}
+}
+
+func WithRecoverMessage(msg string) RecoverOption {
+	return func(cfg *recoverConfig) {
+		if msg != "" {
+			cfg.message = msg
+		}
+	}
+}
+
+func WithRecoverFields(fields Fields) RecoverOption {
+	return func(cfg *recoverConfig) {
+		cfg.fields = MergeFields(cfg.fields, fields)
+	}
+}
+
+func Recover(ctx context.Context, log *Logger, opts ...RecoverOption) {
+	recovered := recover()
+	if recovered == nil {
+		return
+	}
+	logRecovered(ctx, log, recovered, debug.Stack(), opts)
+}
+
+func Go(ctx context.Context, fn func(context.Context), opts ...RecoverOption) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	go func() {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				logRecovered(ctx, nil, recovered, debug.Stack(), opts)
+			}
+		}()
+		fn(ctx)
+	}()
+}
+
+func logRecovered(ctx context.Context, log *Logger, recovered any, stack []byte, opts []RecoverOption) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if log == nil {
+		log = FromContext(ctx)
+	}
+
+	cfg := recoverConfig{message: "panic recovered"}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&cfg)
+		}
+	}
+
+	err, _ := recovered.(error)
+	fields := MergeFields(cfg.fields, Fields{
+		PanicKey: fmt.Sprint(recovered),
+		StackKey: string(stack),
+	})
+	log.LogContext(ctx, LevelPanic, cfg.message, err, 0, fields)
+
+	if cfg.repanic {
+		panic(recovered)
+	}
+}
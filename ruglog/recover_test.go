@@ -0,0 +1,85 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestPanicLogsAndPanicsWithTheError(t *testing.T) {
+	var output bytes.Buffer
+	log := MustNew(WithOutput(&output))
+	cause := errors.New("invariant broken")
+
+	func() {
+		defer func() {
+			if recovered := recover(); recovered != cause {
+				t.Fatalf("expected panic with the original error, got %v", recovered)
+			}
+		}()
+		log.Panic("cannot continue", cause, 4100, nil)
+	}()
+
+	if LevelPanic <= LevelError || LevelPanic >= LevelFatal || LevelPanic.SyslogSeverity() != 2 || LevelPanic.OTLPSeverity() != 20 {
+		t.Fatalf("unexpected PANIC level mapping: %d", int(LevelPanic))
+	}
+	record := decodeRecords(t, output.String())[0]
+	if record["level"] != "PANIC" || record["error"] != "invariant broken" || record["app_code"] != float64(4100) {
+		t.Fatalf("unexpected panic record: %#v", record)
+	}
+}
+
+func TestRecoverLogsStackAndContextFields(t *testing.T) {
+	var output bytes.Buffer
+	log := MustNew(WithOutput(&output))
+	ctx := IntoContext(context.Background(), log.WithField("job_id", "job-7"))
+
+	func() {
+		defer Recover(ctx, nil, WithRecoverFields(Fields{"queue": "emails"}))
+		var items []string
+		_ = items[3]
+	}()
+
+	logged := make(chan struct{}, 2)
+	log.OnRecord(LevelPanic, func(context.Context, Record) { logged <- struct{}{} })
+	Go(ctx, func(context.Context) {
+		panic("worker exploded")
+	})
+	<-logged
+
+	records := decodeRecords(t, output.String())
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records, got %d: %s", len(records), output.String())
+	}
+	first := records[0]
+	if first["level"] != "PANIC" || first["message"] != "panic recovered" || first["job_id"] != "job-7" || first["queue"] != "emails" {
+		t.Fatalf("unexpected recovered record: %#v", first)
+	}
+	if !strings.Contains(first["error"].(string), "index out of range") {
+		t.Fatalf("runtime error must be logged as error: %#v", first)
+	}
+	if !strings.Contains(first[StackKey].(string), "TestRecoverLogsStackAndContextFields") {
+		t.Fatalf("stack must point at the panicking function: %s", first[StackKey])
+	}
+	if records[1][PanicKey] != "worker exploded" || records[1]["job_id"] != "job-7" {
+		t.Fatalf("unexpected goroutine record: %#v", records[1])
+	}
+}
+
+func TestRecoverRepanicsAfterLogging(t *testing.T) {
+	var output bytes.Buffer
+	log := MustNew(WithOutput(&output))
+
+	defer func() {
+		if recovered := recover(); recovered != "boom" {
+			t.Fatalf("expected re-panic with the original value, got %v", recovered)
+		}
+		if !strings.Contains(output.String(), `"panic":"boom"`) {
+			t.Fatalf("panic must be logged before re-panicking: %s", output.String())
+		}
+	}()
+	defer Recover(context.Background(), log, WithRepanic())
+	panic("boom")
+}
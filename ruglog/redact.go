@@ -0,0 +1,90 @@
+package logger
+
+import (
+	"log/slog"
+	"strings"
+)
+
+const Redacted = "[REDACTED]"
+
+var defaultRedactedKeys = normalizeKeySet(
+	"password",
+	"passwd",
+	"secret",
+	"client_secret",
+	"token",
+	"access_token",
+	"refresh_token",
+	"api_key",
+	"apikey",
+	"authorization",
+	"private_key",
+)
+
+type Secret string
+
+func (s Secret) String() string {
+	return Redacted
+}
+
+func (s Secret) GoString() string {
+	return Redacted
+}
+
+func (s Secret) LogValue() slog.Value {
+	return slog.StringValue(Redacted)
+}
+
+func (s Secret) MarshalText() ([]byte, error) {
+	return []byte(Redacted), nil
+}
+
+func (s Secret) Reveal() string {
+	return string(s)
+}
+
+func WithRedactedKeys(keys ...string) Option {
+	return func(cfg *config) error {
+		cfg.redactedKeys = mergeKeySets(cfg.redactedKeys, normalizeKeySet(keys...))
+		return nil
+	}
+}
+
+func WithoutDefaultRedaction() Option {
+	return func(cfg *config) error {
+		for key := range defaultRedactedKeys {
+			delete(cfg.redactedKeys, key)
+		}
+		return nil
+	}
+}
+
+func (p *pipeline) isRedacted(key string) bool {
+	if len(p.redactedKeys) == 0 {
+		return false
+	}
+	_, ok := p.redactedKeys[strings.ToLower(key)]
+	return ok
+}
+
+func normalizeKeySet(values ...string) map[string]struct{} {
+	set := make(map[string]struct{}, len(values))
+	for _, value := range values {
+		key := strings.ToLower(strings.TrimSpace(value))
+		if key == "" {
+			continue
+		}
+		set[key] = struct{}{}
+	}
+	return set
+}
+
+func mergeKeySets(sets ...map[string]struct{}) map[string]struct{} {
+	merged := map[string]struct{}{}
+	for _, set := range sets {
+		for key := range set {
+			merged[key] = struct{}{}
+		}
+	}
+	return merged
+}
@@ -0,0 +1,69 @@
+package logger
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestDefaultKeyRedactionCoversNestedGroupsAndMaps(t *testing.T) {
+	var output bytes.Buffer
+	log := MustNew(
+		WithOutput(&output),
+		WithField("api_key", "default-key"),
+		WithRedactedKeys("Card_Number"),
+	)
+
+	log.WithFields(Fields{"Password": "hunter2"}).WithGroup("request").Info("user signed in", Fields{
+		"user": Fields{
+			"name":        "alice",
+			"card_number": "4111111111111111",
+			"sessions":    []any{map[string]string{"token": "abc", "device": "ios"}},
+		},
+		"headers": map[string][]string{"Authorization": {"Bearer xyz"}},
+	})
+
+	line := output.String()
+	for _, leaked := range []string{"default-key", "hunter2", "4111111111111111", "abc", "Bearer xyz"} {
+		if strings.Contains(line, leaked) {
+			t.Fatalf("secret %q leaked into %s", leaked, line)
+		}
+	}
+
+	record := decodeSingleRecord(t, line)
+	if record["api_key"] != Redacted || record["Password"] != Redacted {
+		t.Fatalf("expected top-level keys to be redacted: %v", record)
+	}
+	user := record["request"].(map[string]any)["user"].(map[string]any)
+	if user["name"] != "alice" || user["card_number"] != Redacted {
+		t.Fatalf("unexpected nested fields: %v", user)
+	}
+	session := user["sessions"].([]any)[0].(map[string]any)
+	if session["token"] != Redacted || session["device"] != "ios" {
+		t.Fatalf("unexpected map inside slice: %v", session)
+	}
+}
+
+func TestSecretRendersRedactedEverywhere(t *testing.T) {
+	secret := Secret("s3cr3t")
+
+	for _, format := range []Format{FormatJSON, FormatText} {
+		var output bytes.Buffer
+		log := MustNew(WithOutput(&output), WithFormat(format), WithoutDefaultRedaction())
+		log.Info("connecting", Fields{
+			"dsn":    secret,
+			"nested": map[string]any{"password": secret},
+		})
+		if strings.Contains(output.String(), "s3cr3t") || !strings.Contains(output.String(), Redacted) {
+			t.Fatalf("%s output leaked the secret: %s", format, output.String())
+		}
+	}
+
+	if rendered := fmt.Sprintf("%v %s %#v", secret, secret, secret); strings.Contains(rendered, "s3cr3t") {
+		t.Fatalf("fmt leaked the secret: %s", rendered)
+	}
+	if secret.Reveal() != "s3cr3t" {
+		t.Fatal("Reveal should return the original value")
+	}
+}
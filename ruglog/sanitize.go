@@ -0,0 +1,115 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+const TruncationMarker = "...[truncated]"
+
+func WithMaxMessageLength(limit int) Option {
+	return func(cfg *config) error {
+		if limit < 0 {
+			return errors.New("logger max message length cannot be negative")
+		}
+		cfg.maxMessageLength = limit
+		return nil
+	}
+}
+
+func WithMaxValueLength(limit int) Option {
+	return func(cfg *config) error {
+		if limit < 0 {
+			return errors.New("logger max value length cannot be negative")
+		}
+		cfg.maxValueLength = limit
+		return nil
+	}
+}
+
+func WithoutControlCharEscaping() Option {
+	return func(cfg *config) error {
+		cfg.escapeControlChars = false
+		return nil
+	}
+}
+
+func (p *pipeline) sanitizesStrings() bool {
+	return p.escapeControlChars || p.maxValueLength > 0
+}
+
+func (p *pipeline) sanitizeKey(key string) string {
+	if !p.escapeControlChars {
+		return key
+	}
+	return escapeControlChars(key, p.jsonEscapes)
+}
+
+func (p *pipeline) sanitizeString(value string, limit int) string {
+	if p.escapeControlChars {
+		value = escapeControlChars(value, p.jsonEscapes)
+	}
+	return truncateString(value, limit)
+}
+
+func escapeControlChars(value string, jsonEscapes bool) string {
+	start := -1
+	for i, r := range value {
+		if isControlRune(r, jsonEscapes) {
+			start = i
+			break
+		}
+	}
+	if start < 0 {
+		return value
+	}
+
+	var builder strings.Builder
+	builder.Grow(len(value) + 8)
+	builder.WriteString(value[:start])
+	for _, r := range value[start:] {
+		switch {
+		case !isControlRune(r, jsonEscapes):
+			builder.WriteRune(r)
+		case r == '\n':
+			builder.WriteString(`\n`)
+		case r == '\r':
+			builder.WriteString(`\r`)
+		case r == '\t':
+			builder.WriteString(`\t`)
+		case r <= 0xff:
+			fmt.Fprintf(&builder, `\x%02x`, r)
+		default:
+			fmt.Fprintf(&builder, `\u%04x`, r)
+		}
+	}
+	return builder.String()
+}
+
+func isControlRune(r rune, jsonEscapes bool) bool {
+	switch {
+	case r == 0x1b, r == 0x7f:
+		return true
+	case r < 0x20, r == 0x2028, r == 0x2029:
+		return !jsonEscapes
+	case r >= 0x80 && r <= 0x9f:
+		return true
+	case r >= 0x202a && r <= 0x202e, r >= 0x2066 && r <= 0x2069:
+		return true
+	default:
+		return false
+	}
+}
+
+func truncateString(value string, limit int) string {
+	if limit <= 0 || len(value) <= limit {
+		return value
+	}
+	cut := limit
+	for cut > 0 && !utf8.RuneStart(value[cut]) {
+		cut--
+	}
+	return value[:cut] + TruncationMarker
+}
@@ -0,0 +1,75 @@
+package logger
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestHostileInputCannotForgeLinesOrEscapeSequences(t *testing.T) {
+	hostile := "curl/8.0\r\n{\"level\":\"ERROR\",\"message\":\"forged\"}\x1b[31mred\x1b[0m\u2028\u202e"
+
+	for _, format := range []Format{FormatJSON, FormatText} {
+		var output bytes.Buffer
+		log := MustNew(WithOutput(&output), WithFormat(format))
+
+		log.WithGroup("req\nuest").Info("request from "+hostile, Fields{
+			"user_agent": hostile,
+			"headers":    map[string][]string{"X-Evil\r\n": {hostile}},
+		})
+
+		line := output.String()
+		if strings.Count(line, "\n") != 1 || !strings.HasSuffix(line, "\n") {
+			t.Fatalf("%s: hostile input produced more than one line: %q", format, line)
+		}
+		for _, raw := range []string{"\r", "\x1b", "\u2028", "\u202e", `\u001b`} {
+			if strings.Contains(line, raw) {
+				t.Fatalf("%s: raw control sequence %q survived: %q", format, raw, line)
+			}
+		}
+		if !strings.Contains(line, `\\x1b[31mred`) && !strings.Contains(line, `\x1b[31mred`) {
+			t.Fatalf("%s: escaped sequence should stay readable: %q", format, line)
+		}
+	}
+
+	var output bytes.Buffer
+	log := MustNew(WithOutput(&output))
+	log.Info("line1\nline2", Fields{"ua": "a\tb", StackKey: "main.run()\n\tmain.go:12", "ansi": "\x1b[2J"})
+	if line := output.String(); !strings.Contains(line, `"line1\nline2"`) || !strings.Contains(line, `"ansi":"\\x1b[2J"`) {
+		t.Fatalf("JSON must leave whitespace to the encoder and escape ANSI itself: %s", line)
+	}
+	record := decodeSingleRecord(t, output.String())
+	if record["message"] != "line1\nline2" || record["ua"] != "a\tb" || record[StackKey] != "main.run()\n\tmain.go:12" {
+		t.Fatalf("JSON values must decode to the logged text: %v", record)
+	}
+}
+
+func TestMessageAndValueLengthLimits(t *testing.T) {
+	var output bytes.Buffer
+	log := MustNew(
+		WithOutput(&output),
+		WithMaxMessageLength(8),
+		WithMaxValueLength(5),
+	)
+
+	log.Info("message that is too long", Fields{
+		"path":   "/api/v1/orders",
+		"short":  "ok",
+		"nested": Fields{"name": "Алексей"},
+		"status": 200,
+	})
+
+	record := decodeSingleRecord(t, output.String())
+	if record["message"] != "message "+TruncationMarker {
+		t.Fatalf("unexpected message: %q", record["message"])
+	}
+	if record["path"] != "/api/"+TruncationMarker || record["short"] != "ok" || record["status"] != float64(200) {
+		t.Fatalf("unexpected value truncation: %v", record)
+	}
+	if name := record["nested"].(map[string]any)["name"]; name != "Ал"+TruncationMarker {
+		t.Fatalf("truncation must not split UTF-8 runes: %q", name)
+	}
+	if _, err := New(WithMaxValueLength(-1)); err == nil {
+		t.Fatal("expected negative limit to be rejected")
+	}
+}
@@ -0,0 +1,225 @@
+package logger
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+type ScrubPattern string
+
+const (
+	ScrubEmail      ScrubPattern = "email"
+	ScrubCardNumber ScrubPattern = "card"
+	ScrubPhone      ScrubPattern = "phone"
+	ScrubJWT        ScrubPattern = "jwt"
+	ScrubAPIKey     ScrubPattern = "api_key"
+)
+
+type ScrubMode string
+
+const (
+	ScrubMask  ScrubMode = "mask"
+	ScrubLast4 ScrubMode = "last4"
+)
+
+var scrubPatternOrder = []ScrubPattern{ScrubJWT, ScrubAPIKey, ScrubEmail, ScrubCardNumber, ScrubPhone}
+
+var apiKeyPrefixes = []string{"sk_", "pk_", "rk_", "sk-", "AKIA", "ghp_", "gho_", "ghu_", "ghs_", "ghr_", "xox", "AIza"}
+
+type scrubRule struct {
+	mask     string
+	expr     *regexp.Regexp
+	mode     ScrubMode
+	trigger  func(string) bool
+	validate func(string) bool
+	digits   bool
+}
+
+var scrubRules = map[ScrubPattern]scrubRule{
+	ScrubEmail: {
+		mask:    "[EMAIL]",
+		expr:    regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9\-]+(?:\.[A-Za-z0-9\-]+)*\.[A-Za-z]{2,}`),
+		mode:    ScrubMask,
+		trigger: func(value string) bool { return strings.IndexByte(value, '@') >= 0 },
+	},
+	ScrubCardNumber: {
+		mask:     "[CARD]",
+		expr:     regexp.MustCompile(`\b\d(?:[ \-]?\d){12,18}\b`),
+		mode:     ScrubLast4,
+		trigger:  func(value string) bool { return countDigits(value) >= 13 },
+		validate: func(match string) bool { return luhnValid(onlyDigits(match)) },
+		digits:   true,
+	},
+	ScrubPhone: {
+		mask: "[PHONE]",
+		expr: regexp.MustCompile(
+			`\+\d{1,3}[ \-]?\(?\d{1,4}\)?(?:[ \-]?\d){5,12}\b` +
+				`|\b8[ \-]?\(?\d{3}\)?[ \-]?\d{3}[ \-]?\d{2}[ \-]?\d{2}\b` +
+				`|(?:\(\d{3}\)|\b\d{3})[ \-]\d{3}[ \-]\d{4}\b`,
+		),
+		mode:    ScrubLast4,
+		trigger: func(value string) bool { return countDigits(value) >= 10 },
+		validate: func(match string) bool {
+			digits := len(onlyDigits(match))
+			return digits >= 10 && digits <= 15
+		},
+		digits: true,
+	},
+	ScrubJWT: {
+		mask:    "[JWT]",
+		expr:    regexp.MustCompile(`\beyJ[A-Za-z0-9_\-]+\.eyJ[A-Za-z0-9_\-]+\.[A-Za-z0-9_\-]*`),
+		mode:    ScrubMask,
+		trigger: func(value string) bool { return strings.Contains(value, "eyJ") },
+	},
+	ScrubAPIKey: {
+		mask: "[API_KEY]",
+		expr: regexp.MustCompile(
+			`\b(?:sk|pk|rk)_(?:live|test)_[A-Za-z0-9]{16,}` +
+				`|\bsk-[A-Za-z0-9_\-]{20,}` +
+				`|\bAKIA[0-9A-Z]{16}\b` +
+				`|\bgh[pousr]_[A-Za-z0-9]{36,}` +
+				`|\bxox[abposr]-[A-Za-z0-9\-]{10,}` +
+				`|\bAIza[0-9A-Za-z_\-]{35}`,
+		),
+		mode: ScrubMask,
+		trigger: func(value string) bool {
+			for _, prefix := range apiKeyPrefixes {
+				if strings.Contains(value, prefix) {
+					return true
+				}
+			}
+			return false
+		},
+	},
+}
+
+type scrubber struct {
+	rules []scrubRule
+}
+
+func WithPIIScrubbing(patterns ...ScrubPattern) Option {
+	return func(cfg *config) error {
+		if len(patterns) == 0 {
+			patterns = scrubPatternOrder
+		}
+		if cfg.scrubModes == nil {
+			cfg.scrubModes = map[ScrubPattern]ScrubMode{}
+		}
+		for _, pattern := range patterns {
+			rule, ok := scrubRules[pattern]
+			if !ok {
+				return fmt.Errorf("unknown scrub pattern %q", pattern)
+			}
+			if _, exists := cfg.scrubModes[pattern]; !exists {
+				cfg.scrubModes[pattern] = rule.mode
+			}
+		}
+		return nil
+	}
+}
+
+func WithScrubMode(pattern ScrubPattern, mode ScrubMode) Option {
+	return func(cfg *config) error {
+		if _, ok := scrubRules[pattern]; !ok {
+			return fmt.Errorf("unknown scrub pattern %q", pattern)
+		}
+		switch mode {
+		case ScrubMask, ScrubLast4:
+		default:
+			return fmt.Errorf("unsupported scrub mode %q", mode)
+		}
+		if cfg.scrubModes == nil {
+			cfg.scrubModes = map[ScrubPattern]ScrubMode{}
+		}
+		cfg.scrubModes[pattern] = mode
+		return nil
+	}
+}
+
+func newScrubber(modes map[ScrubPattern]ScrubMode) *scrubber {
+	if len(modes) == 0 {
+		return nil
+	}
+	s := &scrubber{}
+	for _, pattern := range scrubPatternOrder {
+		mode, ok := modes[pattern]
+		if !ok {
+			continue
+		}
+		rule := scrubRules[pattern]
+		rule.mode = mode
+		s.rules = append(s.rules, rule)
+	}
+	return s
+}
+
+func (s *scrubber) scrub(value string) string {
+	if s == nil || len(value) < 6 {
+		return value
+	}
+	for _, rule := range s.rules {
+		if !rule.trigger(value) {
+			continue
+		}
+		value = rule.expr.ReplaceAllStringFunc(value, rule.replace)
+	}
+	return value
+}
+
+func (r scrubRule) replace(match string) string {
+	if r.validate != nil && !r.validate(match) {
+		return match
+	}
+	if r.mode == ScrubMask {
+		return r.mask
+	}
+	visible := match
+	if r.digits {
+		visible = onlyDigits(match)
+	}
+	if len(visible) > 4 {
+		visible = visible[len(visible)-4:]
+	}
+	return "****" + visible
+}
+
+func countDigits(value string) int {
+	count := 0
+	for i := 0; i < len(value); i++ {
+		if value[i] >= '0' && value[i] <= '9' {
+			count++
+		}
+	}
+	return count
+}
+
+func onlyDigits(value string) string {
+	digits := make([]byte, 0, len(value))
+	for i := 0; i < len(value); i++ {
+		if value[i] >= '0' && value[i] <= '9' {
+			digits = append(digits, value[i])
+		}
+	}
+	return string(digits)
+}
+
+func luhnValid(digits string) bool {
+	if len(digits) < 13 || len(digits) > 19 {
+		return false
+	}
+	sum := 0
+	double := false
+	for i := len(digits) - 1; i >= 0; i-- {
+		digit := int(digits[i] - '0')
+		if double {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+		double = !double
+	}
+	return sum%10 == 0
+}
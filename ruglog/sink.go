@@ -0,0 +1,835 @@
+package logger
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultSinkBatchSize    = 500
+	defaultSinkBatchBytes   = 1 << 20
+	defaultSinkBatchAge     = time.Second
+	defaultSinkQueueSize    = 10000
+	defaultSinkAttempts     = 5
+	defaultSinkMinBackoff   = 250 * time.Millisecond
+	defaultSinkMaxBackoff   = 10 * time.Second
+	defaultSinkTimeout      = 10 * time.Second
+	defaultSinkCloseTimeout = 5 * time.Second
+)
+
+var (
+	ErrSinkClosed    = errors.New("log sink is closed")
+	ErrSinkQueueFull = errors.New("log sink queue is full")
+)
+
+type BatchSender interface {
+	SendBatch(ctx context.Context, lines [][]byte) error
+}
+
+type SinkOption func(*sinkConfig) error
+
+type sinkScope uint16
+
+const (
+	sinkScopeHTTP sinkScope = 1 << iota
+	sinkScopeBatch
+	sinkScopeHost
+	sinkScopeToken
+	sinkScopeLoki
+	sinkScopeElastic
+	sinkScopeGELF
+	sinkScopeSplunk
+	sinkScopeDatadog
+)
+
+type sinkOptionUse struct {
+	name  string
+	scope sinkScope
+}
+
+type sinkConfig struct {
+	used []sinkOptionUse
+
+	client      *http.Client
+	headers     http.Header
+	batchSize   int
+	batchBytes  int
+	batchAge    time.Duration
+	queueSize   int
+	attempts    int
+	minBackoff  time.Duration
+	maxBackoff  time.Duration
+	timeout     time.Duration
+	onError     func(error)
+	lokiLabels  []string
+	lokiFormat  LokiEncoding
+	lokiTenant  string
+	indexPrefix string
+	indexLayout string
+	host        string
+	token       string
+	gzip        bool
+	source      string
+	sourcetype  string
+	index       string
+	service     string
+	tags        []string
+
+	gelfCompression GELFCompression
+	gelfChunkSize   int
+
+	spoolDir          string
+	spoolMaxBytes     int64
+	spoolSegmentBytes int64
+	spoolOverflow     SpoolOverflow
+}
+
+func defaultSinkConfig() sinkConfig {
+	return sinkConfig{
+		client:      &http.Client{},
+		headers:     http.Header{},
+		batchSize:   defaultSinkBatchSize,
+		batchBytes:  defaultSinkBatchBytes,
+		batchAge:    defaultSinkBatchAge,
+		queueSize:   defaultSinkQueueSize,
+		attempts:    defaultSinkAttempts,
+		minBackoff:  defaultSinkMinBackoff,
+		maxBackoff:  defaultSinkMaxBackoff,
+		timeout:     defaultSinkTimeout,
+		lokiLabels:  []string{"service", "level", "route"},
+		lokiFormat:  LokiJSON,
+		indexPrefix: "logs-",
+		indexLayout: "2006.01.02",
+
+		gelfCompression: GELFCompressGzip,
+		gelfChunkSize:   defaultGELFChunkSize,
+
+		spoolMaxBytes:     defaultSpoolMaxBytes,
+		spoolSegmentBytes: defaultSpoolSegmentBytes,
+		spoolOverflow:     SpoolDropOldest,
+	}
+}
+
+func newSinkConfig(backend string, allowed sinkScope, opts []SinkOption) (sinkConfig, error) {
+	cfg := defaultSinkConfig()
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		if err := opt(&cfg); err != nil {
+			return sinkConfig{}, err
+		}
+	}
+	for _, used := range cfg.used {
+		if used.scope&allowed == 0 {
+			return sinkConfig{}, fmt.Errorf("%s is not supported by the %s sink", used.name, backend)
+		}
+	}
+	return cfg, nil
+}
+
+func (cfg *sinkConfig) use(name string, scope sinkScope) {
+	cfg.used = append(cfg.used, sinkOptionUse{name: name, scope: scope})
+}
+
+func WithHTTPClient(client *http.Client) SinkOption {
+	return func(cfg *sinkConfig) error {
+		cfg.use("WithHTTPClient", sinkScopeHTTP)
+		if client == nil {
+			return errors.New("sink http client cannot be nil")
+		}
+		cfg.client = client
+		return nil
+	}
+}
+
+func WithSinkHeader(key string, value string) SinkOption {
+	return func(cfg *sinkConfig) error {
+		cfg.use("WithSinkHeader", sinkScopeHTTP)
+		if strings.TrimSpace(key) == "" {
+			return errors.New("sink header name cannot be empty")
+		}
+		cfg.headers.Set(key, value)
+		return nil
+	}
+}
+
+func WithSinkBasicAuth(username string, password string) SinkOption {
+	return func(cfg *sinkConfig) error {
+		cfg.use("WithSinkBasicAuth", sinkScopeHTTP)
+		request := &http.Request{Header: http.Header{}}
+		request.SetBasicAuth(username, password)
+		cfg.headers.Set("Authorization", request.Header.Get("Authorization"))
+		return nil
+	}
+}
+
+func WithSinkHost(host string) SinkOption {
+	return func(cfg *sinkConfig) error {
+		cfg.use("WithSinkHost", sinkScopeHost)
+		cfg.host = strings.TrimSpace(host)
+		return nil
+	}
+}
+
+func WithSinkToken(token string) SinkOption {
+	return func(cfg *sinkConfig) error {
+		cfg.use("WithSinkToken", sinkScopeToken)
+		token = strings.TrimSpace(token)
+		if token == "" {
+			return errors.New("sink token cannot be empty")
+		}
+		cfg.token = token
+		return nil
+	}
+}
+
+func WithSinkGzip() SinkOption {
+	return func(cfg *sinkConfig) error {
+		cfg.use("WithSinkGzip", sinkScopeHTTP)
+		cfg.gzip = true
+		return nil
+	}
+}
+
+func WithBatchSize(records int, bytes int) SinkOption {
+	return func(cfg *sinkConfig) error {
+		cfg.use("WithBatchSize", sinkScopeBatch)
+		if records <= 0 {
+			return fmt.Errorf("sink batch size must be positive, got %d", records)
+		}
+		cfg.batchSize = records
+		if bytes > 0 {
+			cfg.batchBytes = bytes
+		}
+		return nil
+	}
+}
+
+func WithBatchAge(age time.Duration) SinkOption {
+	return func(cfg *sinkConfig) error {
+		cfg.use("WithBatchAge", sinkScopeBatch)
+		if age <= 0 {
+			return fmt.Errorf("sink batch age must be positive, got %s", age)
+		}
+		cfg.batchAge = age
+		return nil
+	}
+}
+
+func WithQueueSize(records int) SinkOption {
+	return func(cfg *sinkConfig) error {
+		cfg.use("WithQueueSize", sinkScopeBatch)
+		if records <= 0 {
+			return fmt.Errorf("sink queue size must be positive, got %d", records)
+		}
+		cfg.queueSize = records
+		return nil
+	}
+}
+
+func WithRetry(attempts int, minBackoff time.Duration, maxBackoff time.Duration) SinkOption {
+	return func(cfg *sinkConfig) error {
+		cfg.use("WithRetry", sinkScopeBatch)
+		if attempts <= 0 {
+			return fmt.Errorf("sink retry attempts must be positive, got %d", attempts)
+		}
+		if minBackoff < 0 || maxBackoff < minBackoff {
+			return fmt.Errorf("invalid sink backoff range %s..%s", minBackoff, maxBackoff)
+		}
+		cfg.attempts = attempts
+		cfg.minBackoff = minBackoff
+		cfg.maxBackoff = maxBackoff
+		return nil
+	}
+}
+
+func WithSinkTimeout(timeout time.Duration) SinkOption {
+	return func(cfg *sinkConfig) error {
+		if timeout <= 0 {
+			return fmt.Errorf("sink timeout must be positive, got %s", timeout)
+		}
+		cfg.timeout = timeout
+		return nil
+	}
+}
+
+func WithSinkErrorHandler(fn func(error)) SinkOption {
+	return func(cfg *sinkConfig) error {
+		cfg.use("WithSinkErrorHandler", sinkScopeBatch)
+		cfg.onError = fn
+		return nil
+	}
+}
+
+type SinkStats struct {
+	Sent    uint64
+	Dropped uint64
+	Retries uint64
+}
+
+type BatchWriter struct {
+	sender BatchSender
+	cfg    sinkConfig
+	spool  *diskSpool
+
+	mu           sync.Mutex
+	pending      [][]byte
+	pendingBytes int
+	closed       bool
+
+	sending   chan struct{}
+	flushCh   chan struct{}
+	ctx       context.Context
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+	closeOnce sync.Once
+	closeErr  error
+
+	sent    atomic.Uint64
+	dropped atomic.Uint64
+	retries atomic.Uint64
+}
+
+func NewBatchWriter(sender BatchSender, opts ...SinkOption) (*BatchWriter, error) {
+	if sender == nil {
+		return nil, errors.New("batch sender cannot be nil")
+	}
+	cfg, err := newSinkConfig("batch", sinkScopeBatch, opts)
+	if err != nil {
+		return nil, err
+	}
+	return newBatchWriter(sender, cfg)
+}
+
+func newBatchWriter(sender BatchSender, cfg sinkConfig) (*BatchWriter, error) {
+	var spool *diskSpool
+	if cfg.spoolDir != "" {
+		opened, err := openDiskSpool(cfg.spoolDir, cfg.spoolMaxBytes, cfg.spoolSegmentBytes, cfg.spoolOverflow)
+		if err != nil {
+			return nil, err
+		}
+		spool = opened
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	writer := &BatchWriter{
+		sender:  sender,
+		cfg:     cfg,
+		spool:   spool,
+		sending: make(chan struct{}, 1),
+		flushCh: make(chan struct{}, 1),
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+	writer.wg.Add(1)
+	go writer.loop()
+	return writer, nil
+}
+
+func (w *BatchWriter) Write(p []byte) (int, error) {
+	line := bytes.TrimRight(p, "\r\n")
+	if len(line) == 0 {
+		return len(p), nil
+	}
+
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return 0, ErrSinkClosed
+	}
+
+	var full bool
+	if w.spool != nil {
+		dropped, err := w.spool.append(line)
+		if dropped > 0 {
+			w.dropped.Add(uint64(dropped))
+		}
+		if err != nil {
+			w.mu.Unlock()
+			return 0, err
+		}
+		full = w.spool.records >= w.cfg.batchSize
+	} else {
+		if len(w.pending) >= w.cfg.queueSize {
+			w.mu.Unlock()
+			w.dropped.Add(1)
+			return 0, ErrSinkQueueFull
+		}
+		copied := make([]byte, len(line))
+		copy(copied, line)
+		w.pending = append(w.pending, copied)
+		w.pendingBytes += len(copied)
+		full = len(w.pending) >= w.cfg.batchSize || w.pendingBytes >= w.cfg.batchBytes
+	}
+	w.mu.Unlock()
+
+	if full {
+		select {
+		case w.flushCh <- struct{}{}:
+		default:
+		}
+	}
+	return len(p), nil
+}
+
+func (w *BatchWriter) Flush() error {
+	ctx, cancel := context.WithTimeout(context.Background(), w.cfg.timeout*time.Duration(w.cfg.attempts))
+	defer cancel()
+	return w.flush(ctx)
+}
+
+func (s *sharedState) flushSinks() {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultSinkCloseTimeout)
+	defer cancel()
+	for _, sink := range s.sinks {
+		_ = sink.flush(ctx)
+	}
+}
+
+func (w *BatchWriter) Close() error {
+	w.closeOnce.Do(func() {
+		w.mu.Lock()
+		w.closed = true
+		w.mu.Unlock()
+
+		w.cancel()
+		w.wg.Wait()
+
+		ctx, cancel := context.WithTimeout(context.Background(), defaultSinkCloseTimeout)
+		defer cancel()
+		w.closeErr = w.flush(ctx)
+		if w.spool != nil {
+			w.closeErr = errors.Join(w.closeErr, w.spool.close())
+			return
+		}
+		if ctx.Err() != nil {
+			w.closeErr = errors.Join(w.closeErr, w.dropPending(ctx.Err()))
+		}
+	})
+	return w.closeErr
+}
+
+func (w *BatchWriter) Stats() SinkStats {
+	return SinkStats{
+		Sent:    w.sent.Load(),
+		Dropped: w.dropped.Load(),
+		Retries: w.retries.Load(),
+	}
+}
+
+func (w *BatchWriter) loop() {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(w.cfg.batchAge)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.ctx.Done():
+			return
+		case <-ticker.C:
+		case <-w.flushCh:
+		}
+		_ = w.flush(w.ctx)
+	}
+}
+
+func (w *BatchWriter) flush(ctx context.Context) error {
+	select {
+	case w.sending <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	defer func() { <-w.sending }()
+
+	if w.spool != nil {
+		return w.flushSpool(ctx)
+	}
+
+	var joined error
+	for {
+		batch := w.takeBatch()
+		if len(batch) == 0 {
+			return joined
+		}
+		remaining, err := w.send(ctx, batch)
+		joined = errors.Join(joined, err)
+		if len(remaining) > 0 {
+			if ctx.Err() != nil {
+				w.requeue(remaining)
+				return joined
+			}
+			joined = errors.Join(joined, w.drop(len(remaining), err))
+		}
+	}
+}
+
+func (w *BatchWriter) flushSpool(ctx context.Context) error {
+	var joined error
+	for {
+		w.mu.Lock()
+		batch, position, err := w.spool.read(w.cfg.batchSize, w.cfg.batchBytes)
+		w.mu.Unlock()
+		if err != nil || len(batch) == 0 {
+			return errors.Join(joined, err)
+		}
+
+		remaining, err := w.send(ctx, batch)
+		joined = errors.Join(joined, err)
+		if len(remaining) == len(batch) {
+			return joined
+		}
+
+		w.mu.Lock()
+		err = w.spool.ack(position)
+		for _, line := range remaining {
+			dropped, appendErr := w.spool.append(line)
+			w.dropped.Add(uint64(dropped))
+			err = errors.Join(err, appendErr)
+		}
+		w.mu.Unlock()
+		if err != nil || len(remaining) > 0 {
+			return errors.Join(joined, err)
+		}
+	}
+}
+
+func (w *BatchWriter) takeBatch() [][]byte {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	count := 0
+	size := 0
+	for count < len(w.pending) && count < w.cfg.batchSize {
+		next := size + len(w.pending[count])
+		if count > 0 && next > w.cfg.batchBytes {
+			break
+		}
+		size = next
+		count++
+	}
+	if count == 0 {
+		return nil
+	}
+
+	batch := w.pending[:count:count]
+	w.pending = w.pending[count:]
+	w.pendingBytes -= size
+	if len(w.pending) == 0 {
+		w.pending = nil
+		w.pendingBytes = 0
+	}
+	return batch
+}
+
+func (w *BatchWriter) requeue(batch [][]byte) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	size := 0
+	for _, line := range batch {
+		size += len(line)
+	}
+	w.pending = append(batch[:len(batch):len(batch)], w.pending...)
+	w.pendingBytes += size
+}
+
+func (w *BatchWriter) dropPending(cause error) error {
+	w.mu.Lock()
+	count := len(w.pending)
+	w.pending = nil
+	w.pendingBytes = 0
+	w.mu.Unlock()
+
+	return w.drop(count, cause)
+}
+
+func (w *BatchWriter) drop(count int, cause error) error {
+	if count == 0 {
+		return nil
+	}
+	w.dropped.Add(uint64(count))
+	err := fmt.Errorf("log sink dropped %d records: %w", count, cause)
+	w.reportError(err)
+	return err
+}
+
+func (w *BatchWriter) send(ctx context.Context, batch [][]byte) ([][]byte, error) {
+	backoff := w.cfg.minBackoff
+	var joined error
+	var lastErr error
+	for attempt := 1; attempt <= w.cfg.attempts; attempt++ {
+		if attempt > 1 {
+			w.retries.Add(1)
+			if err := sleepContext(ctx, backoff); err != nil {
+				return batch, errors.Join(joined, err)
+			}
+			backoff = nextBackoff(backoff, w.cfg.maxBackoff)
+		}
+
+		attemptCtx, cancel := context.WithTimeout(ctx, w.cfg.timeout)
+		err := w.sender.SendBatch(attemptCtx, batch)
+		cancel()
+		if err == nil {
+			w.sent.Add(uint64(len(batch)))
+			return nil, joined
+		}
+		if ctx.Err() != nil {
+			return batch, errors.Join(joined, ctx.Err())
+		}
+
+		var partial *PartialBatchError
+		if errors.As(err, &partial) {
+			w.sent.Add(uint64(max(len(batch)-len(partial.Retry)-partial.Dropped, 0)))
+			joined = errors.Join(joined, w.drop(partial.Dropped, partial.Err))
+			if len(partial.Retry) == 0 {
+				return nil, joined
+			}
+			batch = partial.Retry
+			lastErr = partial.Err
+			continue
+		}
+
+		lastErr = err
+		if !IsRetryableSinkError(err) {
+			return nil, errors.Join(joined, w.drop(len(batch), err))
+		}
+	}
+	return batch, lastErr
+}
+
+func (w *BatchWriter) reportError(err error) {
+	if w.cfg.onError != nil && err != nil {
+		w.cfg.onError(err)
+	}
+}
+
+func nextBackoff(current time.Duration, limit time.Duration) time.Duration {
+	if current <= 0 {
+		return limit
+	}
+	next := current * 2
+	if next > limit {
+		return limit
+	}
+	return next
+}
+
+func sleepContext(ctx context.Context, delay time.Duration) error {
+	if delay <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+type PartialBatchError struct {
+	Retry   [][]byte
+	Dropped int
+	Err     error
+}
+
+func (e *PartialBatchError) Error() string {
+	return fmt.Sprintf("log sink rejected part of a batch (%d retryable, %d dropped): %v", len(e.Retry), e.Dropped, e.Err)
+}
+
+func (e *PartialBatchError) Unwrap() error {
+	return e.Err
+}
+
+type HTTPStatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *HTTPStatusError) Error() string {
+	if e.Body == "" {
+		return fmt.Sprintf("log sink responded with status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("log sink responded with status %d: %s", e.StatusCode, e.Body)
+}
+
+func IsRetryableSinkError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	var statusErr *HTTPStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode == http.StatusTooManyRequests || statusErr.StatusCode >= 500
+	}
+	return true
+}
+
+func postSinkRequest(ctx context.Context, cfg sinkConfig, url string, contentType string, body []byte, headers http.Header) ([]byte, error) {
+	encoding := headers.Get("Content-Encoding")
+	if cfg.gzip && encoding == "" {
+		compressed, err := gzipBytes(body)
+		if err != nil {
+			return nil, err
+		}
+		body = compressed
+		encoding = "gzip"
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Content-Type", contentType)
+	if encoding != "" {
+		request.Header.Set("Content-Encoding", encoding)
+	}
+	for key, values := range cfg.headers {
+		request.Header[key] = append([]string(nil), values...)
+	}
+	for key, values := range headers {
+		request.Header[key] = append([]string(nil), values...)
+	}
+
+	response, err := cfg.client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	payload, err := io.ReadAll(io.LimitReader(response.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return payload, &HTTPStatusError{
+			StatusCode: response.StatusCode,
+			Body:       strings.TrimSpace(string(truncateBytes(payload, 512))),
+		}
+	}
+	return payload, nil
+}
+
+func sinkEndpoint(endpoint string, defaultPath string) (string, error) {
+	endpoint = strings.TrimSpace(endpoint)
+	if endpoint == "" {
+		return "", errors.New("sink endpoint cannot be empty")
+	}
+	parsed, err := url.Parse(endpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid sink endpoint %q: %w", endpoint, err)
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return "", fmt.Errorf("sink endpoint %q must use http or https", endpoint)
+	}
+	if parsed.Path == "" || parsed.Path == "/" {
+		parsed.Path = defaultPath
+	}
+	return parsed.String(), nil
+}
+
+func gzipBytes(body []byte) ([]byte, error) {
+	var buffer bytes.Buffer
+	writer := gzip.NewWriter(&buffer)
+	if _, err := writer.Write(body); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+func sinkHost(cfg sinkConfig) string {
+	if cfg.host != "" {
+		return cfg.host
+	}
+	if host, err := os.Hostname(); err == nil && host != "" {
+		return host
+	}
+	return "unknown"
+}
+
+func truncateBytes(value []byte, limit int) []byte {
+	if len(value) <= limit {
+		return value
+	}
+	return value[:limit]
+}
+
+func decodeRecordLine(line []byte) map[string]any {
+	decoder := json.NewDecoder(bytes.NewReader(line))
+	decoder.UseNumber()
+
+	var record map[string]any
+	if err := decoder.Decode(&record); err != nil || record == nil {
+		return map[string]any{"message": string(line)}
+	}
+	return record
+}
+
+func recordTime(record map[string]any, fallback time.Time) time.Time {
+	switch raw := record["timestamp"].(type) {
+	case string:
+		parsed, err := time.Parse(time.RFC3339Nano, raw)
+		if err != nil {
+			return fallback
+		}
+		return parsed
+	case json.Number:
+		epoch, err := raw.Int64()
+		if err != nil {
+			return fallback
+		}
+		return epochTime(epoch)
+	default:
+		return fallback
+	}
+}
+
+func epochTime(epoch int64) time.Time {
+	magnitude := epoch
+	if magnitude < 0 {
+		magnitude = -magnitude
+	}
+	switch {
+	case magnitude < 1e11:
+		return time.Unix(epoch, 0)
+	case magnitude < 1e14:
+		return time.UnixMilli(epoch)
+	case magnitude < 1e17:
+		return time.UnixMicro(epoch)
+	default:
+		return time.Unix(0, epoch)
+	}
+}
+
+func recordString(record map[string]any, key string) string {
+	switch value := record[key].(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case json.Number:
+		return value.String()
+	default:
+		return fmt.Sprint(value)
+	}
+}
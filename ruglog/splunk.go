@@ -0,0 +1,124 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	splunkEventPath = "/services/collector/event"
+	splunkSinkScope = sinkScopeHTTP | sinkScopeHost | sinkScopeToken | sinkScopeSplunk
+)
+
+func WithSplunkSource(source string) SinkOption {
+	return func(cfg *sinkConfig) error {
+		cfg.use("WithSplunkSource", sinkScopeSplunk)
+		cfg.source = strings.TrimSpace(source)
+		return nil
+	}
+}
+
+func WithSplunkSourcetype(sourcetype string) SinkOption {
+	return func(cfg *sinkConfig) error {
+		cfg.use("WithSplunkSourcetype", sinkScopeSplunk)
+		cfg.sourcetype = strings.TrimSpace(sourcetype)
+		return nil
+	}
+}
+
+func WithSplunkIndex(index string) SinkOption {
+	return func(cfg *sinkConfig) error {
+		cfg.use("WithSplunkIndex", sinkScopeSplunk)
+		cfg.index = strings.TrimSpace(index)
+		return nil
+	}
+}
+
+type SplunkSender struct {
+	endpoint string
+	host     string
+	cfg      sinkConfig
+	now      func() time.Time
+}
+
+func NewSplunkSender(endpoint string, opts ...SinkOption) (*SplunkSender, error) {
+	cfg, err := newSinkConfig("splunk", splunkSinkScope, opts)
+	if err != nil {
+		return nil, err
+	}
+	return newSplunkSender(endpoint, cfg)
+}
+
+func newSplunkSender(endpoint string, cfg sinkConfig) (*SplunkSender, error) {
+	if cfg.token == "" {
+		return nil, errors.New("splunk HEC token is required")
+	}
+	eventURL, err := sinkEndpoint(endpoint, splunkEventPath)
+	if err != nil {
+		return nil, err
+	}
+	return &SplunkSender{
+		endpoint: eventURL,
+		host:     sinkHost(cfg),
+		cfg:      cfg,
+		now:      time.Now,
+	}, nil
+}
+
+func NewSplunkWriter(endpoint string, opts ...SinkOption) (*BatchWriter, error) {
+	cfg, err := newSinkConfig("splunk", splunkSinkScope|sinkScopeBatch, opts)
+	if err != nil {
+		return nil, err
+	}
+	sender, err := newSplunkSender(endpoint, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return newBatchWriter(sender, cfg)
+}
+
+type splunkEvent struct {
+	Time       json.Number    `json:"time"`
+	Host       string         `json:"host,omitempty"`
+	Source     string         `json:"source,omitempty"`
+	Sourcetype string         `json:"sourcetype,omitempty"`
+	Index      string         `json:"index,omitempty"`
+	Event      map[string]any `json:"event"`
+}
+
+func (s *SplunkSender) SendBatch(ctx context.Context, lines [][]byte) error {
+	if len(lines) == 0 {
+		return nil
+	}
+
+	var body bytes.Buffer
+	now := s.now()
+	for _, line := range lines {
+		record := decodeRecordLine(line)
+		timestamp := recordTime(record, now)
+		encoded, err := json.Marshal(splunkEvent{
+			Time:       json.Number(fmt.Sprintf("%d.%03d", timestamp.Unix(), timestamp.Nanosecond()/int(time.Millisecond))),
+			Host:       s.host,
+			Source:     s.cfg.source,
+			Sourcetype: s.cfg.sourcetype,
+			Index:      s.cfg.index,
+			Event:      record,
+		})
+		if err != nil {
+			return err
+		}
+		body.Write(encoded)
+		body.WriteByte('\n')
+	}
+
+	headers := http.Header{}
+	headers.Set("Authorization", "Splunk "+s.cfg.token)
+	_, err := postSinkRequest(ctx, s.cfg, s.endpoint, "application/json", body.Bytes(), headers)
+	return err
+}
@@ -0,0 +1,98 @@
+package logger
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSplunkWriterSendsGzippedEventEnvelopes(t *testing.T) {
+	var events []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/services/collector/event" {
+			t.Errorf("unexpected HEC path: %s", r.URL.Path)
+		}
+		if r.Header.Get("Authorization") != "Splunk hec-token" || r.Header.Get("Content-Encoding") != "gzip" {
+			t.Errorf("unexpected HEC headers: %v", r.Header)
+		}
+		reader, err := gzip.NewReader(r.Body)
+		if err != nil {
+			t.Errorf("body is not gzip: %v", err)
+			return
+		}
+		scanner := bufio.NewScanner(reader)
+		for scanner.Scan() {
+			var event map[string]any
+			if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+				t.Errorf("invalid HEC event %q: %v", scanner.Text(), err)
+			}
+			events = append(events, event)
+		}
+		_, _ = w.Write([]byte(`{"text":"Success","code":0}`))
+	}))
+	defer server.Close()
+
+	sink, err := NewSplunkWriter(server.URL,
+		WithSinkToken("hec-token"),
+		WithSinkGzip(),
+		WithSinkHost("api-1"),
+		WithSplunkSource("orders-api"),
+		WithSplunkSourcetype("_json"),
+		WithSplunkIndex("main"),
+		WithBatchAge(time.Hour),
+	)
+	if err != nil {
+		t.Fatalf("failed to create splunk writer: %v", err)
+	}
+
+	if _, err := sink.Write([]byte(`{"timestamp":"2026-05-11T13:00:00.25Z","level":"INFO","message":"created","order":7}` + "\n")); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatalf("close failed: %v", err)
+	}
+
+	if len(events) != 1 {
+		t.Fatalf("expected one event, got %d", len(events))
+	}
+	event := events[0]
+	if event["time"] != 1778504400.25 || event["host"] != "api-1" {
+		t.Fatalf("unexpected time or host: %#v", event)
+	}
+	if event["source"] != "orders-api" || event["sourcetype"] != "_json" || event["index"] != "main" {
+		t.Fatalf("unexpected envelope metadata: %#v", event)
+	}
+	payload, ok := event["event"].(map[string]any)
+	if !ok || payload["message"] != "created" || payload["order"] != float64(7) {
+		t.Fatalf("unexpected event payload: %#v", event["event"])
+	}
+}
+
+func TestSinkConstructorsRejectOptionsOfOtherBackends(t *testing.T) {
+	token := WithSinkToken("hec-token")
+	for _, opt := range []SinkOption{WithLokiLabels("service"), WithElasticIndex("logs-", ""), WithGELFChunkSize(512), WithDatadogTags("env:prod")} {
+		if _, err := NewSplunkWriter("http://splunk:8088", token, opt); err == nil || !strings.Contains(err.Error(), "splunk sink") {
+			t.Fatalf("splunk writer must reject a foreign option, got %v", err)
+		}
+	}
+	if _, err := NewLokiWriter("http://loki:3100", WithSinkToken("secret")); err == nil {
+		t.Fatal("loki writer must reject WithSinkToken")
+	}
+	if _, err := NewLokiSender("http://loki:3100", WithBatchSize(10, 0)); err == nil {
+		t.Fatal("a sender has no batch queue and must reject WithBatchSize")
+	}
+	if _, err := NewBatchWriter(&recordingSender{}, WithSinkGzip()); err == nil {
+		t.Fatal("a custom batch writer must reject HTTP options")
+	}
+
+	writer, err := NewSplunkWriter("http://splunk:8088", token, WithSinkGzip(), WithSinkHost("api-1"), WithSplunkIndex("main"), WithRetry(2, 0, 0))
+	if err != nil {
+		t.Fatalf("splunk writer rejected its own options: %v", err)
+	}
+	_ = writer.Close()
+}
@@ -0,0 +1,366 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultSpoolMaxBytes     int64 = 256 << 20
+	defaultSpoolSegmentBytes int64 = 8 << 20
+	spoolSegmentExt                = ".seg"
+	spoolCursorFile                = "cursor"
+)
+
+var ErrSpoolFull = errors.New("log spool is full")
+
+type SpoolOverflow string
+
+const (
+	SpoolDropOldest SpoolOverflow = "drop_oldest"
+	SpoolDropNewest SpoolOverflow = "drop_newest"
+)
+
+func WithSpool(dir string) SinkOption {
+	return func(cfg *sinkConfig) error {
+		cfg.use("WithSpool", sinkScopeBatch)
+		dir = filepath.Clean(strings.TrimSpace(dir))
+		if dir == "." || dir == "" {
+			return errors.New("spool directory cannot be empty")
+		}
+		cfg.spoolDir = dir
+		return nil
+	}
+}
+
+func WithSpoolLimits(maxBytes int64, segmentBytes int64, overflow SpoolOverflow) SinkOption {
+	return func(cfg *sinkConfig) error {
+		cfg.use("WithSpoolLimits", sinkScopeBatch)
+		if maxBytes <= 0 || segmentBytes <= 0 || segmentBytes > maxBytes {
+			return fmt.Errorf("invalid spool limits: max %d bytes, segment %d bytes", maxBytes, segmentBytes)
+		}
+		switch overflow {
+		case SpoolDropOldest, SpoolDropNewest:
+		default:
+			return fmt.Errorf("unsupported spool overflow policy %q", overflow)
+		}
+		cfg.spoolMaxBytes = maxBytes
+		cfg.spoolSegmentBytes = segmentBytes
+		cfg.spoolOverflow = overflow
+		return nil
+	}
+}
+
+type spoolSegment struct {
+	seq     uint64
+	size    int64
+	records int
+}
+
+type spoolPosition struct {
+	seq    uint64
+	offset int64
+	count  int
+}
+
+type diskSpool struct {
+	dir          string
+	maxBytes     int64
+	segmentBytes int64
+	overflow     SpoolOverflow
+
+	segments   []*spoolSegment
+	active     *os.File
+	readOffset int64
+	total      int64
+	records    int
+}
+
+func openDiskSpool(dir string, maxBytes int64, segmentBytes int64, overflow SpoolOverflow) (*diskSpool, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	spool := &diskSpool{
+		dir:          dir,
+		maxBytes:     maxBytes,
+		segmentBytes: segmentBytes,
+		overflow:     overflow,
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, spoolSegmentExt) {
+			continue
+		}
+		seq, err := strconv.ParseUint(strings.TrimSuffix(name, spoolSegmentExt), 10, 64)
+		if err != nil {
+			continue
+		}
+		spool.segments = append(spool.segments, &spoolSegment{seq: seq})
+	}
+	sort.Slice(spool.segments, func(i, j int) bool {
+		return spool.segments[i].seq < spool.segments[j].seq
+	})
+
+	cursorSeq, cursorOffset := spool.loadCursor()
+	kept := spool.segments[:0]
+	for _, segment := range spool.segments {
+		if segment.seq < cursorSeq {
+			_ = os.Remove(spool.segmentPath(segment.seq))
+			continue
+		}
+		kept = append(kept, segment)
+	}
+	spool.segments = kept
+
+	for i, segment := range spool.segments {
+		offset := int64(0)
+		if i == 0 && segment.seq == cursorSeq {
+			offset = cursorOffset
+		}
+		size, records, err := spool.scanSegment(segment.seq, offset)
+		if err != nil {
+			return nil, err
+		}
+		segment.size = size
+		segment.records = records
+		spool.total += size
+		spool.records += records
+		if i == 0 {
+			spool.readOffset = min(offset, size)
+		}
+	}
+
+	if err := spool.rotate(); err != nil {
+		return nil, err
+	}
+	return spool, nil
+}
+
+func (s *diskSpool) append(line []byte) (int, error) {
+	need := int64(len(line) + 1)
+	dropped := 0
+	for s.total+need > s.maxBytes {
+		if s.overflow == SpoolDropNewest || len(s.segments) == 0 {
+			return 1, ErrSpoolFull
+		}
+		if len(s.segments) == 1 {
+			if s.segments[0].size == 0 {
+				return 1, ErrSpoolFull
+			}
+			if err := s.rotate(); err != nil {
+				return dropped, err
+			}
+		}
+		count, err := s.removeOldest()
+		dropped += count
+		if err != nil {
+			return dropped, err
+		}
+	}
+
+	active := s.segments[len(s.segments)-1]
+	if active.size > 0 && active.size+need > s.segmentBytes {
+		if err := s.rotate(); err != nil {
+			return dropped, err
+		}
+		active = s.segments[len(s.segments)-1]
+	}
+
+	record := make([]byte, 0, need)
+	record = append(append(record, line...), '\n')
+	if _, err := s.active.Write(record); err != nil {
+		return dropped, err
+	}
+	active.size += need
+	active.records++
+	s.total += need
+	s.records++
+	return dropped, nil
+}
+
+func (s *diskSpool) read(maxRecords int, maxBytes int) ([][]byte, spoolPosition, error) {
+	for len(s.segments) > 0 {
+		first := s.segments[0]
+		if s.readOffset < first.size {
+			break
+		}
+		if len(s.segments) == 1 {
+			return nil, spoolPosition{}, nil
+		}
+		if _, err := s.removeOldest(); err != nil {
+			return nil, spoolPosition{}, err
+		}
+	}
+	if len(s.segments) == 0 {
+		return nil, spoolPosition{}, nil
+	}
+
+	first := s.segments[0]
+	file, err := os.Open(s.segmentPath(first.seq))
+	if err != nil {
+		return nil, spoolPosition{}, err
+	}
+	defer file.Close()
+	if _, err := file.Seek(s.readOffset, io.SeekStart); err != nil {
+		return nil, spoolPosition{}, err
+	}
+
+	reader := bufio.NewReader(io.LimitReader(file, first.size-s.readOffset))
+	position := spoolPosition{seq: first.seq, offset: s.readOffset}
+	var lines [][]byte
+	size := 0
+	for len(lines) < maxRecords {
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			break
+		}
+		trimmed := bytes.TrimRight(line, "\n")
+		if len(lines) > 0 && size+len(trimmed) > maxBytes {
+			break
+		}
+		lines = append(lines, trimmed)
+		size += len(trimmed)
+		position.offset += int64(len(line))
+		position.count++
+	}
+	return lines, position, nil
+}
+
+func (s *diskSpool) ack(position spoolPosition) error {
+	if len(s.segments) == 0 || s.segments[0].seq != position.seq || position.offset < s.readOffset {
+		return nil
+	}
+	first := s.segments[0]
+	s.readOffset = position.offset
+	first.records -= position.count
+	s.records -= position.count
+
+	if s.readOffset >= first.size && len(s.segments) > 1 {
+		_, err := s.removeOldest()
+		return err
+	}
+	return s.saveCursor(first.seq, s.readOffset)
+}
+
+func (s *diskSpool) close() error {
+	if s.active == nil {
+		return nil
+	}
+	err := s.active.Close()
+	s.active = nil
+	return err
+}
+
+func (s *diskSpool) rotate() error {
+	seq := uint64(1)
+	if len(s.segments) > 0 {
+		seq = s.segments[len(s.segments)-1].seq + 1
+	}
+	file, err := os.OpenFile(s.segmentPath(seq), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if s.active != nil {
+		_ = s.active.Close()
+	}
+	s.active = file
+	s.segments = append(s.segments, &spoolSegment{seq: seq})
+	if len(s.segments) == 1 {
+		s.readOffset = 0
+	}
+	return nil
+}
+
+func (s *diskSpool) removeOldest() (int, error) {
+	oldest := s.segments[0]
+	s.segments = s.segments[1:]
+	s.total -= oldest.size
+	s.records -= oldest.records
+	s.readOffset = 0
+
+	if err := os.Remove(s.segmentPath(oldest.seq)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return oldest.records, err
+	}
+	if len(s.segments) > 0 {
+		return oldest.records, s.saveCursor(s.segments[0].seq, 0)
+	}
+	return oldest.records, nil
+}
+
+func (s *diskSpool) scanSegment(seq uint64, offset int64) (int64, int, error) {
+	file, err := os.Open(s.segmentPath(seq))
+	if err != nil {
+		return 0, 0, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return 0, 0, err
+	}
+	if offset > info.Size() {
+		offset = info.Size()
+	}
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return 0, 0, err
+	}
+
+	records := 0
+	complete := offset
+	reader := bufio.NewReader(file)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			break
+		}
+		records++
+		complete += int64(len(line))
+	}
+	return complete, records, nil
+}
+
+func (s *diskSpool) loadCursor() (uint64, int64) {
+	raw, err := os.ReadFile(filepath.Join(s.dir, spoolCursorFile))
+	if err != nil {
+		return 0, 0
+	}
+	parts := strings.Fields(string(raw))
+	if len(parts) != 2 {
+		return 0, 0
+	}
+	seq, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil {
+		return 0, 0
+	}
+	offset, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || offset < 0 {
+		return seq, 0
+	}
+	return seq, offset
+}
+
+func (s *diskSpool) saveCursor(seq uint64, offset int64) error {
+	path := filepath.Join(s.dir, spoolCursorFile)
+	temporary := path + ".tmp"
+	if err := os.WriteFile(temporary, []byte(fmt.Sprintf("%d %d\n", seq, offset)), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(temporary, path)
+}
+
+func (s *diskSpool) segmentPath(seq uint64) string {
+	return filepath.Join(s.dir, fmt.Sprintf("%020d%s", seq, spoolSegmentExt))
+}
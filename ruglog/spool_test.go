@@ -0,0 +1,172 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+type recordingSender struct {
+	mu      sync.Mutex
+	fail    bool
+	batches [][]string
+}
+
+func (s *recordingSender) SendBatch(_ context.Context, lines [][]byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.fail {
+		return &HTTPStatusError{StatusCode: 503}
+	}
+	batch := make([]string, 0, len(lines))
+	for _, line := range lines {
+		batch = append(batch, string(line))
+	}
+	s.batches = append(s.batches, batch)
+	return nil
+}
+
+type stallingSender struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (s *stallingSender) SendBatch(_ context.Context, _ [][]byte) error {
+	select {
+	case s.started <- struct{}{}:
+	default:
+	}
+	<-s.release
+	return nil
+}
+
+func TestFlushStopsWaitingForBackgroundSendWhenContextEnds(t *testing.T) {
+	sender := &stallingSender{started: make(chan struct{}, 1), release: make(chan struct{})}
+	sink, err := NewBatchWriter(sender, WithBatchSize(1, 0), WithBatchAge(time.Hour))
+	if err != nil {
+		t.Fatalf("failed to create sink: %v", err)
+	}
+	defer sink.Close()
+	defer close(sender.release)
+
+	if _, err := sink.Write([]byte(`{"message":"in flight"}` + "\n")); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	<-sender.started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	started := time.Now()
+	if err := sink.flush(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the flush to give up with its context, got %v", err)
+	}
+	if elapsed := time.Since(started); elapsed > time.Second {
+		t.Fatalf("flush waited %s for the background send", elapsed)
+	}
+}
+
+func TestSpooledBatchWriterReplaysRecordsAfterRestart(t *testing.T) {
+	dir := t.TempDir()
+	opts := []SinkOption{
+		WithSpool(dir),
+		WithSpoolLimits(1<<20, 64, SpoolDropOldest),
+		WithBatchSize(3, 0),
+		WithBatchAge(time.Hour),
+		WithRetry(1, 0, 0),
+	}
+
+	down := &recordingSender{fail: true}
+	first, err := NewBatchWriter(down, opts...)
+	if err != nil {
+		t.Fatalf("failed to create spooled writer: %v", err)
+	}
+	for i := 1; i <= 5; i++ {
+		if _, err := fmt.Fprintf(first, `{"message":"record-%d"}`+"\n", i); err != nil {
+			t.Fatalf("write %d failed: %v", i, err)
+		}
+	}
+	if err := first.Close(); err == nil {
+		t.Fatal("expected close to report the unreachable backend")
+	}
+	if stats := first.Stats(); stats.Sent != 0 || stats.Dropped != 0 {
+		t.Fatalf("spooled records must not be dropped: %+v", stats)
+	}
+
+	up := &recordingSender{}
+	second, err := NewBatchWriter(up, opts...)
+	if err != nil {
+		t.Fatalf("failed to reopen spool: %v", err)
+	}
+	if _, err := second.Write([]byte(`{"message":"record-6"}` + "\n")); err != nil {
+		t.Fatalf("write after restart failed: %v", err)
+	}
+	if err := second.Close(); err != nil {
+		t.Fatalf("replay failed: %v", err)
+	}
+
+	var replayed []string
+	for _, batch := range up.batches {
+		if len(batch) > 3 {
+			t.Fatalf("batch exceeds configured size: %v", batch)
+		}
+		replayed = append(replayed, batch...)
+	}
+	if len(replayed) != 6 {
+		t.Fatalf("expected 6 replayed records, got %v", replayed)
+	}
+	for i, line := range replayed {
+		if expected := fmt.Sprintf(`{"message":"record-%d"}`, i+1); line != expected {
+			t.Fatalf("record %d out of order: got %s, want %s", i, line, expected)
+		}
+	}
+
+	third, err := NewBatchWriter(up, opts...)
+	if err != nil {
+		t.Fatalf("failed to reopen drained spool: %v", err)
+	}
+	if err := third.Close(); err != nil || third.Stats().Sent != 0 {
+		t.Fatalf("drained spool should not replay again: %v, %+v", err, third.Stats())
+	}
+}
+
+func TestDiskSpoolOverflowPolicies(t *testing.T) {
+	line := []byte(`{"message":"0123456789"}`)
+	recordSize := int64(len(line) + 1)
+
+	oldest, err := openDiskSpool(t.TempDir(), recordSize*4, recordSize*2, SpoolDropOldest)
+	if err != nil {
+		t.Fatalf("failed to open spool: %v", err)
+	}
+	defer oldest.close()
+
+	dropped := 0
+	for i := 0; i < 6; i++ {
+		count, err := oldest.append(line)
+		if err != nil {
+			t.Fatalf("drop-oldest append %d failed: %v", i, err)
+		}
+		dropped += count
+	}
+	if dropped != 2 || oldest.records != 4 {
+		t.Fatalf("expected the oldest segment to be dropped, got dropped=%d records=%d", dropped, oldest.records)
+	}
+
+	newest, err := openDiskSpool(t.TempDir(), recordSize*2, recordSize, SpoolDropNewest)
+	if err != nil {
+		t.Fatalf("failed to open spool: %v", err)
+	}
+	defer newest.close()
+
+	for i := 0; i < 2; i++ {
+		if _, err := newest.append(line); err != nil {
+			t.Fatalf("drop-newest append %d failed: %v", i, err)
+		}
+	}
+	if count, err := newest.append(line); !errors.Is(err, ErrSpoolFull) || count != 1 {
+		t.Fatalf("expected a full spool to reject new records, got %d, %v", count, err)
+	}
+}
@@ -0,0 +1,195 @@
+package logger
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+const (
+	DepthLimitMarker = "[depth limit]"
+	CycleMarker      = "[cycle]"
+)
+
+func WithMaxDepth(depth int) Option {
+	return func(cfg *config) error {
+		if depth <= 0 {
+			return errors.New("logger max depth must be positive")
+		}
+		cfg.maxDepth = depth
+		return nil
+	}
+}
+
+type structField struct {
+	index     []int
+	name      string
+	redact    bool
+	omitEmpty bool
+}
+
+var structPlans sync.Map
+
+func structPlan(typ reflect.Type) []structField {
+	if plan, ok := structPlans.Load(typ); ok {
+		return plan.([]structField)
+	}
+	plan, _ := structPlans.LoadOrStore(typ, buildStructPlan(typ, nil, map[reflect.Type]bool{}))
+	return plan.([]structField)
+}
+
+func buildStructPlan(typ reflect.Type, prefix []int, seen map[reflect.Type]bool) []structField {
+	seen[typ] = true
+	defer delete(seen, typ)
+
+	var own, promoted []structField
+	for i := range typ.NumField() {
+		field := typ.Field(i)
+		index := append(append([]int(nil), prefix...), i)
+		tag, skip := parseLogTag(field)
+		if skip {
+			continue
+		}
+
+		embedded := field.Type
+		if embedded.Kind() == reflect.Pointer {
+			embedded = embedded.Elem()
+		}
+		if field.Anonymous && tag.name == "" && embedded.Kind() == reflect.Struct && !seen[embedded] {
+			promoted = append(promoted, buildStructPlan(embedded, index, seen)...)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if tag.name == "" {
+			tag.name = field.Name
+		}
+		tag.index = index
+		own = append(own, tag)
+	}
+
+	names := make(map[string]bool, len(own))
+	for _, field := range own {
+		names[field.name] = true
+	}
+	for _, field := range promoted {
+		if !names[field.name] {
+			names[field.name] = true
+			own = append(own, field)
+		}
+	}
+	return own
+}
+
+func parseLogTag(field reflect.StructField) (structField, bool) {
+	var parsed structField
+	if tag, ok := field.Tag.Lookup("log"); ok {
+		if tag == "-" {
+			return parsed, true
+		}
+		for _, option := range strings.Split(tag, ",") {
+			option = strings.TrimSpace(option)
+			switch {
+			case option == "redact":
+				parsed.redact = true
+			case option == "omitempty":
+				parsed.omitEmpty = true
+			case strings.HasPrefix(option, "name="):
+				parsed.name = strings.TrimPrefix(option, "name=")
+			}
+		}
+		return parsed, false
+	}
+
+	if tag, ok := field.Tag.Lookup("json"); ok {
+		if tag == "-" {
+			return parsed, true
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		parsed.name = name
+		parsed.omitEmpty = strings.Contains(","+options+",", ",omitempty,")
+	}
+	return parsed, false
+}
+
+func (p *pipeline) rewriteStruct(rv reflect.Value, depth int, state *rewriteState) any {
+	if rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil
+		}
+		key := visitKey{ptr: rv.Pointer(), typ: rv.Type()}
+		if _, ok := state.visiting[key]; ok {
+			return CycleMarker
+		}
+		if state.visiting == nil {
+			state.visiting = map[visitKey]struct{}{}
+		}
+		state.visiting[key] = struct{}{}
+		defer delete(state.visiting, key)
+		rv = rv.Elem()
+	}
+	plan := structPlan(rv.Type())
+	result := make(Fields, len(plan))
+	for _, field := range plan {
+		value, ok := fieldByIndex(rv, field.index)
+		if !ok || !value.CanInterface() || field.omitEmpty && isEmptyValue(value) {
+			continue
+		}
+		name := p.sanitizeKey(field.name)
+		if field.redact {
+			result[name] = Redacted
+			continue
+		}
+		result[name] = p.rewriteField(field.name, value.Interface(), depth, state)
+	}
+	return result
+}
+
+type visitKey struct {
+	ptr uintptr
+	typ reflect.Type
+}
+
+func fieldByIndex(rv reflect.Value, index []int) (reflect.Value, bool) {
+	for i, position := range index {
+		if i > 0 && rv.Kind() == reflect.Pointer {
+			if rv.IsNil() {
+				return reflect.Value{}, false
+			}
+			rv = rv.Elem()
+		}
+		rv = rv.Field(position)
+	}
+	return rv, true
+}
+
+func isEmptyValue(rv reflect.Value) bool {
+	switch rv.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return rv.Len() == 0
+	case reflect.Interface, reflect.Pointer:
+		return rv.IsNil()
+	default:
+		return rv.IsZero()
+	}
+}
+
+func isCompositeValue(rv reflect.Value) bool {
+	switch rv.Kind() {
+	case reflect.Map, reflect.Array, reflect.Struct:
+		return true
+	case reflect.Slice:
+		return rv.Type().Elem().Kind() != reflect.Uint8
+	default:
+		return isStructValue(rv)
+	}
+}
+
+func isStructValue(rv reflect.Value) bool {
+	if rv.Kind() == reflect.Pointer {
+		return rv.Type().Elem().Kind() == reflect.Struct
+	}
+	return rv.Kind() == reflect.Struct
+}
@@ -0,0 +1,80 @@
+package logger
+
+import (
+	"bytes"
+	"testing"
+)
+
+type auditInfo struct {
+	CreatedBy string `log:"name=created_by"`
+	internal  string
+}
+
+type customer struct {
+	auditInfo
+	ID       int    `json:"id"`
+	Email    string `log:"redact"`
+	Password string
+	Token    string    `log:"-"`
+	Nickname string    `log:"name=nick,omitempty"`
+	Phone    string    `json:"phone,omitempty"`
+	Manager  *customer `log:"name=manager,omitempty"`
+}
+
+type treeNode struct {
+	Name     string
+	Parent   *treeNode
+	Children []*treeNode
+}
+
+func TestStructTagsControlEncodedFields(t *testing.T) {
+	var output bytes.Buffer
+	log := MustNew(WithOutput(&output))
+
+	log.Info("customer loaded", Fields{
+		"customer": customer{
+			auditInfo: auditInfo{CreatedBy: "admin", internal: "hidden"},
+			ID:        42,
+			Email:     "jane@example.com",
+			Password:  "hunter2",
+			Token:     "tok_live",
+			Manager:   &customer{ID: 7, Nickname: "boss"},
+		},
+	})
+
+	record := decodeRecords(t, output.String())[0]
+	encoded := record["customer"].(map[string]any)
+	expected := map[string]any{"id": float64(42), "Email": Redacted, "Password": Redacted, "created_by": "admin"}
+	for key, value := range expected {
+		if encoded[key] != value {
+			t.Fatalf("customer[%q] = %#v, want %#v in %#v", key, encoded[key], value, encoded)
+		}
+	}
+	for _, key := range []string{"Token", "nick", "phone", "internal", "auditInfo"} {
+		if _, ok := encoded[key]; ok {
+			t.Fatalf("field %q must be omitted: %#v", key, encoded)
+		}
+	}
+	if manager := encoded["manager"].(map[string]any); manager["nick"] != "boss" || manager["id"] != float64(7) {
+		t.Fatalf("nested struct not encoded: %#v", manager)
+	}
+}
+
+func TestStructEncodingStopsAtCyclesAndDepthLimit(t *testing.T) {
+	root := &treeNode{Name: "root"}
+	child := &treeNode{Name: "child", Parent: root}
+	root.Children = []*treeNode{child}
+
+	var output bytes.Buffer
+	MustNew(WithOutput(&output)).Info("tree", Fields{"node": root})
+	MustNew(WithOutput(&output), WithMaxDepth(2)).Info("deep", Fields{"deep": Fields{"a": Fields{"b": Fields{"c": 1}}}})
+
+	records := decodeRecords(t, output.String())
+	children := records[0]["node"].(map[string]any)["Children"].([]any)
+	if parent := children[0].(map[string]any)["Parent"]; parent != CycleMarker {
+		t.Fatalf("expected cycle marker, got %#v", parent)
+	}
+	if deep := records[1]["deep"].(map[string]any)["a"].(map[string]any)["b"]; deep != DepthLimitMarker {
+		t.Fatalf("expected depth limit marker, got %#v", deep)
+	}
+}
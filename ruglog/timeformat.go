@@ -0,0 +1,100 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+type TimestampEncoding string
+
+const (
+	TimestampLayout    TimestampEncoding = "layout"
+	TimestampUnix      TimestampEncoding = "unix"
+	TimestampUnixMilli TimestampEncoding = "unix_ms"
+	TimestampUnixNano  TimestampEncoding = "unix_ns"
+)
+
+type DurationEncoding string
+
+const (
+	DurationNative  DurationEncoding = "native"
+	DurationMillis  DurationEncoding = "ms"
+	DurationSeconds DurationEncoding = "seconds"
+	DurationString  DurationEncoding = "string"
+)
+
+func WithTimeLocation(location *time.Location) Option {
+	return func(cfg *config) error {
+		if location == nil {
+			return errors.New("logger time location cannot be nil")
+		}
+		cfg.location = location
+		return nil
+	}
+}
+
+func WithTimeZone(name string) Option {
+	return func(cfg *config) error {
+		location, err := time.LoadLocation(strings.TrimSpace(name))
+		if err != nil {
+			return fmt.Errorf("unknown logger time zone %q: %w", name, err)
+		}
+		cfg.location = location
+		return nil
+	}
+}
+
+func WithTimestampEncoding(encoding TimestampEncoding) Option {
+	return func(cfg *config) error {
+		switch encoding {
+		case TimestampLayout, TimestampUnix, TimestampUnixMilli, TimestampUnixNano:
+			cfg.timestampEncoding = encoding
+			return nil
+		default:
+			return fmt.Errorf("unsupported timestamp encoding %q", encoding)
+		}
+	}
+}
+
+func WithDurationEncoding(encoding DurationEncoding) Option {
+	return func(cfg *config) error {
+		switch encoding {
+		case DurationNative, DurationMillis, DurationSeconds, DurationString:
+			cfg.durationEncoding = encoding
+			return nil
+		default:
+			return fmt.Errorf("unsupported duration encoding %q", encoding)
+		}
+	}
+}
+
+func encodeTimestamp(value time.Time, cfg config) slog.Value {
+	switch cfg.timestampEncoding {
+	case TimestampUnix:
+		return slog.Int64Value(value.Unix())
+	case TimestampUnixMilli:
+		return slog.Int64Value(value.UnixMilli())
+	case TimestampUnixNano:
+		return slog.Int64Value(value.UnixNano())
+	}
+	if cfg.location != nil {
+		value = value.In(cfg.location)
+	}
+	return slog.StringValue(value.Format(cfg.timeFormat))
+}
+
+func encodeDuration(value time.Duration, encoding DurationEncoding) slog.Value {
+	switch encoding {
+	case DurationMillis:
+		return slog.Int64Value(value.Milliseconds())
+	case DurationSeconds:
+		return slog.Float64Value(value.Seconds())
+	case DurationString:
+		return slog.StringValue(value.String())
+	default:
+		return slog.DurationValue(value)
+	}
+}
@@ -0,0 +1,59 @@
+package logger
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEpochTimestampAndDurationEncodingInJSON(t *testing.T) {
+	moment := time.Date(2026, 5, 11, 13, 0, 0, 250_000_000, time.UTC)
+	var output bytes.Buffer
+	log := MustNew(
+		WithOutput(&output),
+		WithClock(func() time.Time { return moment }),
+		WithTimestampEncoding(TimestampUnixMilli),
+		WithDurationEncoding(DurationMillis),
+	)
+
+	log.WithGroup("db").Info("query finished", Fields{"elapsed": 1500 * time.Millisecond})
+
+	expected := `{"timestamp":1778504400250,"level":"INFO","message":"query finished","db":{"elapsed":1500}}` + "\n"
+	if output.String() != expected {
+		t.Fatalf("unexpected output:\n%s\nwant:\n%s", output.String(), expected)
+	}
+	if parsed := recordTime(decodeRecordLine(bytes.TrimSpace(output.Bytes())), time.Time{}); !parsed.Equal(moment) {
+		t.Fatalf("sinks should read epoch millis back, got %v", parsed)
+	}
+}
+
+func TestTimeZoneAndDurationEncodingInText(t *testing.T) {
+	moment := time.Date(2026, 5, 11, 13, 0, 0, 0, time.UTC)
+	var output bytes.Buffer
+	log := MustNew(
+		WithOutput(&output),
+		WithFormat(FormatText),
+		WithTimeFormat(time.RFC3339),
+		WithTimeZone("Asia/Tokyo"),
+		WithClock(func() time.Time { return moment }),
+		WithDurationEncoding(DurationSeconds),
+	)
+
+	log.Info("job done", Fields{"took": 2500 * time.Millisecond, "started": moment})
+
+	line := output.String()
+	for _, expected := range []string{
+		"timestamp=2026-05-11T22:00:00+09:00",
+		"took=2.5",
+		"started=2026-05-11T22:00:00.000+09:00",
+	} {
+		if !strings.Contains(line, expected) {
+			t.Fatalf("expected %q in %q", expected, line)
+		}
+	}
+
+	if _, err := New(WithTimeZone("Mars/Olympus_Mons")); err == nil {
+		t.Fatal("expected unknown time zone to be rejected")
+	}
+}
@@ -0,0 +1,16 @@
+package logger
+
+import "log/slog"
+
+type lazyValue func() any
+
+func Lazy(fn func() any) slog.LogValuer {
+	return lazyValue(fn)
+}
+
+func (fn lazyValue) LogValue() slog.Value {
+	if fn == nil {
+		return slog.AnyValue(nil)
+	}
+	return slog.AnyValue(normalizeFieldValue(fn()))
+}
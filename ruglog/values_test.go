@@ -0,0 +1,117 @@
+package logger
+
+import (
+	"bytes"
+	"log/slog"
+	"net/netip"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+type accountRef struct {
+	ID    int
+	Token string
+}
+
+func (a accountRef) LogValue() slog.Value {
+	return slog.GroupValue(slog.Int("id", a.ID))
+}
+
+type orderID struct{ value int }
+
+func (o orderID) String() string { return "ord-" + string(rune('0'+o.value)) }
+
+type currency struct{ code string }
+
+func (c currency) MarshalText() ([]byte, error) { return []byte(strings.ToUpper(c.code)), nil }
+
+func TestLazyValuesAreEvaluatedOnlyForEmittedRecords(t *testing.T) {
+	var output bytes.Buffer
+	log := MustNew(WithOutput(&output))
+
+	calls := 0
+	expensive := Lazy(func() any {
+		calls++
+		return Fields{"rows": 3}
+	})
+
+	log.Debug("skipped", Fields{"stats": expensive})
+	if calls != 0 {
+		t.Fatalf("lazy value evaluated for a disabled record: %d calls", calls)
+	}
+
+	log.Info("emitted", Fields{
+		"stats":    expensive,
+		"nested":   Fields{"stats": expensive},
+		"password": Lazy(func() any { t.Fatalf("redacted lazy value must not be evaluated"); return nil }),
+	})
+	if calls != 2 {
+		t.Fatalf("expected one evaluation per occurrence, got %d", calls)
+	}
+
+	record := decodeRecords(t, output.String())[0]
+	if record["stats"].(map[string]any)["rows"] != float64(3) || record["nested"].(map[string]any)["stats"].(map[string]any)["rows"] != float64(3) {
+		t.Fatalf("lazy values not rendered: %#v", record)
+	}
+	if record["password"] != Redacted {
+		t.Fatalf("redacted key leaked: %#v", record)
+	}
+}
+
+func TestLogValuerStringerAndTextMarshalerRenderConsistently(t *testing.T) {
+	fields := Fields{
+		"account":  accountRef{ID: 7, Token: "tok_live_secret"},
+		"order":    orderID{value: 5},
+		"currency": currency{code: "rub"},
+		"items":    []any{orderID{value: 1}, currency{code: "usd"}},
+		"nested":   Fields{"account": accountRef{ID: 8}},
+	}
+
+	var jsonOutput, textOutput bytes.Buffer
+	MustNew(WithOutput(&jsonOutput)).Info("paid", fields)
+	MustNew(WithOutput(&textOutput), WithFormat(FormatText)).Info("paid", fields)
+
+	if strings.Contains(jsonOutput.String(), "tok_live_secret") || strings.Contains(textOutput.String(), "tok_live_secret") {
+		t.Fatalf("LogValue must replace the struct:\n%s\n%s", jsonOutput.String(), textOutput.String())
+	}
+
+	record := decodeRecords(t, jsonOutput.String())[0]
+	if record["order"] != "ord-5" || record["currency"] != "RUB" {
+		t.Fatalf("unexpected JSON rendering: %#v", record)
+	}
+	if record["account"].(map[string]any)["id"] != float64(7) || record["nested"].(map[string]any)["account"].(map[string]any)["id"] != float64(8) {
+		t.Fatalf("LogValuer inside Fields not resolved: %#v", record)
+	}
+	if items := record["items"].([]any); items[0] != "ord-1" || items[1] != "USD" {
+		t.Fatalf("unexpected slice rendering: %#v", items)
+	}
+
+	for _, expected := range []string{"order=ord-5", "currency=RUB", "account.id=7", `items="[ord-1 USD]"`} {
+		if !strings.Contains(textOutput.String(), expected) {
+			t.Fatalf("missing %q in text output: %s", expected, textOutput.String())
+		}
+	}
+}
+
+func TestNilPointersWithMethodsRenderAsNull(t *testing.T) {
+	var output bytes.Buffer
+	log := MustNew(WithOutput(&output))
+
+	log.Info("nil values", Fields{
+		"url":  (*url.URL)(nil),
+		"addr": (*netip.Addr)(nil),
+		"urls": []*url.URL{nil},
+	})
+
+	record := decodeRecords(t, output.String())[0]
+	if value, ok := record["url"]; !ok || value != nil {
+		t.Fatalf("nil *url.URL must render as null: %#v", record)
+	}
+	if value, ok := record["addr"]; !ok || value != nil {
+		t.Fatalf("nil *netip.Addr must render as null: %#v", record)
+	}
+	if urls := record["urls"].([]any); len(urls) != 1 || urls[0] != nil {
+		t.Fatalf("slice with a nil *url.URL must render as [null]: %#v", record)
+	}
+}
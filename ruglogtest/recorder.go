@@ -0,0 +1,329 @@
+package ruglogtest
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	logger "github.com/PrototypeSirius/ruglogger/ruglog"
+)
+
+type Entry struct {
+	Time    time.Time
+	Level   logger.Level
+	Message string
+	Fields  logger.Fields
+	Source  *slog.Source
+}
+
+type Option func(*config)
+
+type config struct {
+	level         logger.Level
+	passthrough   bool
+	installGlobal bool
+	loggerOptions []logger.Option
+}
+
+func WithLevel(level logger.Level) Option {
+	return func(cfg *config) {
+		cfg.level = level
+	}
+}
+
+func WithoutPassthrough() Option {
+	return func(cfg *config) {
+		cfg.passthrough = false
+	}
+}
+
+func WithoutDefault() Option {
+	return func(cfg *config) {
+		cfg.installGlobal = false
+	}
+}
+
+func WithLoggerOptions(opts ...logger.Option) Option {
+	return func(cfg *config) {
+		cfg.loggerOptions = append(cfg.loggerOptions, opts...)
+	}
+}
+
+type Recorder struct {
+	t      testing.TB
+	cfg    config
+	logger *logger.Logger
+
+	mu      sync.Mutex
+	entries []Entry
+	done    bool
+}
+
+func New(t testing.TB, opts ...Option) *Recorder {
+	t.Helper()
+
+	cfg := config{
+		level:         logger.LevelTrace,
+		passthrough:   true,
+		installGlobal: true,
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&cfg)
+		}
+	}
+
+	recorder := &Recorder{t: t, cfg: cfg}
+	loggerOptions := append([]logger.Option{}, cfg.loggerOptions...)
+	loggerOptions = append(loggerOptions, logger.WithHandler(&recordHandler{recorder: recorder}))
+	log, err := logger.New(loggerOptions...)
+	if err != nil {
+		t.Fatalf("ruglogtest: failed to create logger: %v", err)
+	}
+	recorder.logger = log
+
+	var previous *logger.Logger
+	if cfg.installGlobal {
+		previous = logger.SetDefault(log)
+	}
+	t.Cleanup(func() {
+		recorder.mu.Lock()
+		recorder.done = true
+		recorder.mu.Unlock()
+		if cfg.installGlobal {
+			logger.SetDefault(previous)
+		}
+		_ = log.Close()
+	})
+	return recorder
+}
+
+func (r *Recorder) Logger() *logger.Logger {
+	return r.logger
+}
+
+func (r *Recorder) Entries() []Entry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	copied := make([]Entry, len(r.entries))
+	copy(copied, r.entries)
+	return copied
+}
+
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = nil
+}
+
+func (r *Recorder) AssertLogged(t testing.TB, level logger.Level, msg string, fields logger.Fields) Entry {
+	t.Helper()
+
+	entries := r.Entries()
+	for _, entry := range entries {
+		if entry.Level == level && entry.Message == msg && containsFields(entry.Fields, fields) {
+			return entry
+		}
+	}
+	t.Errorf("ruglogtest: no %s record %q with fields %v\nrecorded:\n%s", level, msg, fields, formatEntries(entries))
+	return Entry{}
+}
+
+func (r *Recorder) RequireNoErrors(t testing.TB) {
+	t.Helper()
+
+	var failures []Entry
+	for _, entry := range r.Entries() {
+		if entry.Level >= logger.LevelError {
+			failures = append(failures, entry)
+		}
+	}
+	if len(failures) > 0 {
+		t.Fatalf("ruglogtest: expected no error records, got %d:\n%s", len(failures), formatEntries(failures))
+	}
+}
+
+func (r *Recorder) record(entry Entry) {
+	r.mu.Lock()
+	r.entries = append(r.entries, entry)
+	passthrough := r.cfg.passthrough && !r.done
+	r.mu.Unlock()
+
+	if passthrough {
+		r.t.Log(formatEntry(entry))
+	}
+}
+
+type recordHandler struct {
+	recorder *Recorder
+	attrs    []slog.Attr
+	groups   []string
+}
+
+func (h *recordHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return logger.Level(level) >= h.recorder.cfg.level
+}
+
+func (h *recordHandler) Handle(_ context.Context, record slog.Record) error {
+	fields := logger.Fields{}
+	target := fields
+	for _, attr := range h.attrs {
+		addAttr(target, attr)
+	}
+	for _, group := range h.groups {
+		nested, ok := target[group].(logger.Fields)
+		if !ok {
+			nested = logger.Fields{}
+			target[group] = nested
+		}
+		target = nested
+	}
+	record.Attrs(func(attr slog.Attr) bool {
+		addAttr(target, attr)
+		return true
+	})
+
+	h.recorder.record(Entry{
+		Time:    record.Time,
+		Level:   logger.Level(record.Level),
+		Message: record.Message,
+		Fields:  fields,
+		Source:  recordSource(record.PC),
+	})
+	return nil
+}
+
+func (h *recordHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	child := h.clone()
+	if len(child.groups) == 0 {
+		child.attrs = append(child.attrs, attrs...)
+		return child
+	}
+
+	args := make([]any, 0, len(attrs))
+	for _, attr := range attrs {
+		args = append(args, attr)
+	}
+	nested := slog.Group(child.groups[len(child.groups)-1], args...)
+	for i := len(child.groups) - 2; i >= 0; i-- {
+		nested = slog.Group(child.groups[i], nested)
+	}
+	child.attrs = append(child.attrs, nested)
+	return child
+}
+
+func (h *recordHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	child := h.clone()
+	child.groups = append(child.groups, name)
+	return child
+}
+
+func (h *recordHandler) clone() *recordHandler {
+	return &recordHandler{
+		recorder: h.recorder,
+		attrs:    append([]slog.Attr(nil), h.attrs...),
+		groups:   append([]string(nil), h.groups...),
+	}
+}
+
+func addAttr(fields logger.Fields, attr slog.Attr) {
+	value := attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return
+	}
+	if value.Kind() != slog.KindGroup {
+		fields[attr.Key] = value.Any()
+		return
+	}
+
+	target := fields
+	if attr.Key != "" {
+		nested, ok := fields[attr.Key].(logger.Fields)
+		if !ok {
+			nested = logger.Fields{}
+			fields[attr.Key] = nested
+		}
+		target = nested
+	}
+	for _, child := range value.Group() {
+		addAttr(target, child)
+	}
+}
+
+func recordSource(pc uintptr) *slog.Source {
+	if pc == 0 {
+		return nil
+	}
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	return &slog.Source{
+		Function: frame.Function,
+		File:     frame.File,
+		Line:     frame.Line,
+	}
+}
+
+func containsFields(actual logger.Fields, expected logger.Fields) bool {
+	for key, want := range expected {
+		got, ok := actual[key]
+		if !ok || !equalValues(got, want) {
+			return false
+		}
+	}
+	return true
+}
+
+func equalValues(got any, want any) bool {
+	if wantFields, ok := want.(logger.Fields); ok {
+		gotFields, ok := got.(logger.Fields)
+		return ok && containsFields(gotFields, wantFields)
+	}
+	if reflect.DeepEqual(got, want) {
+		return true
+	}
+	if err, ok := want.(error); ok {
+		want = err.Error()
+	}
+	return fmt.Sprint(got) == fmt.Sprint(want)
+}
+
+func formatEntries(entries []Entry) string {
+	if len(entries) == 0 {
+		return "  (none)"
+	}
+	lines := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		lines = append(lines, "  "+formatEntry(entry))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func formatEntry(entry Entry) string {
+	keys := make([]string, 0, len(entry.Fields))
+	for key := range entry.Fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var builder strings.Builder
+	builder.WriteString(entry.Level.String())
+	builder.WriteByte(' ')
+	builder.WriteString(fmt.Sprintf("%q", entry.Message))
+	for _, key := range keys {
+		builder.WriteString(fmt.Sprintf(" %s=%v", key, entry.Fields[key]))
+	}
+	return builder.String()
+}
@@ -0,0 +1,73 @@
+package ruglogtest
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	logger "github.com/PrototypeSirius/ruglogger/ruglog"
+)
+
+func TestRecorderCapturesStructuredRecords(t *testing.T) {
+	recorder := New(t)
+
+	logger.Get().WithField("component", "worker").WithGroup("job").WithField("queue", "emails").Info("processed", logger.Fields{
+		"attempt": 2,
+	})
+	recorder.Logger().Error("charge failed", errors.New("card declined"), 42, nil)
+
+	entry := recorder.AssertLogged(t, logger.LevelInfo, "processed", logger.Fields{
+		"component": "worker",
+		"job":       logger.Fields{"queue": "emails", "attempt": 2},
+	})
+	if entry.Source == nil || !strings.HasSuffix(entry.Source.File, "recorder_test.go") {
+		t.Fatalf("expected source to point at the test, got %+v", entry.Source)
+	}
+	recorder.AssertLogged(t, logger.LevelError, "charge failed", logger.Fields{
+		"error":    "card declined",
+		"app_code": 42,
+	})
+
+	if entries := recorder.Entries(); len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+}
+
+func TestRecorderAssertionsAndDefaultCleanup(t *testing.T) {
+	previous := logger.MustNew()
+	logger.SetDefault(previous)
+	t.Cleanup(logger.ResetDefaultForTest)
+
+	t.Run("scoped", func(t *testing.T) {
+		recorder := New(t, WithLevel(logger.LevelInfo), WithoutPassthrough())
+		if logger.Get() != recorder.Logger() {
+			t.Fatal("recorder logger should be installed as the default")
+		}
+
+		logger.Debug("hidden", 0, nil)
+		logger.Warn("visible", 7, nil)
+		recorder.RequireNoErrors(t)
+		if entries := recorder.Entries(); len(entries) != 1 || entries[0].Message != "visible" {
+			t.Fatalf("expected only the warning to be recorded, got %+v", entries)
+		}
+
+		probe := &failureProbe{TB: t}
+		recorder.AssertLogged(probe, logger.LevelWarn, "visible", logger.Fields{"app_code": 8})
+		if !probe.failed {
+			t.Fatal("AssertLogged should fail on mismatched fields")
+		}
+	})
+
+	if logger.Get() != previous {
+		t.Fatal("default logger should be restored after the test")
+	}
+}
+
+type failureProbe struct {
+	testing.TB
+	failed bool
+}
+
+func (p *failureProbe) Errorf(string, ...any) {
+	p.failed = true
+}